@@ -1,5 +1,7 @@
 package arn
 
+import "strings"
+
 // Ctx maintains location information for constructing context-aware ARNs.
 type Ctx struct{ Partition, Region, Account string }
 
@@ -70,3 +72,80 @@ func resType(parts []string) string {
 	}
 	return r
 }
+
+// IAMGroup returns the ARN of an IAM group.
+func (c Ctx) IAMGroup(path, name string) ARN {
+	return c.iam("group", path, name)
+}
+
+// IAMInstanceProfile returns the ARN of an IAM instance profile.
+func (c Ctx) IAMInstanceProfile(path, name string) ARN {
+	return c.iam("instance-profile", path, name)
+}
+
+// IAMPolicy returns the ARN of a customer-managed IAM policy.
+func (c Ctx) IAMPolicy(path, name string) ARN {
+	return c.iam("policy", path, name)
+}
+
+// IAMRole returns the ARN of an IAM role.
+func (c Ctx) IAMRole(path, name string) ARN {
+	return c.iam("role", path, name)
+}
+
+// IAMUser returns the ARN of an IAM user.
+func (c Ctx) IAMUser(path, name string) ARN {
+	return c.iam("user", path, name)
+}
+
+// S3Bucket returns the ARN of an S3 bucket.
+func (c Ctx) S3Bucket(name string) ARN { return c.New("s3", name) }
+
+// S3Object returns the ARN of an S3 object. Key may contain wildcards.
+func (c Ctx) S3Object(bucket, key string) ARN {
+	return c.New("s3", bucket, "/", key)
+}
+
+// LambdaFunction returns the ARN of a Lambda function. The optional qualifier
+// specifies a function version or alias.
+func (c Ctx) LambdaFunction(name, qualifier string) ARN {
+	if qualifier == "" {
+		return c.New("lambda", "function:", name)
+	}
+	return c.New("lambda", "function:", name, ":", qualifier)
+}
+
+// SQSQueue returns the ARN of an SQS queue.
+func (c Ctx) SQSQueue(name string) ARN { return c.New("sqs", name) }
+
+// SNSTopic returns the ARN of an SNS topic.
+func (c Ctx) SNSTopic(name string) ARN { return c.New("sns", name) }
+
+// KMSKey returns the ARN of a KMS key.
+func (c Ctx) KMSKey(id string) ARN { return c.New("kms", "key/", id) }
+
+// KMSAlias returns the ARN of a KMS key alias. The "alias/" name prefix is
+// optional.
+func (c Ctx) KMSAlias(name string) ARN {
+	return c.New("kms", "alias/", strings.TrimPrefix(name, "alias/"))
+}
+
+// DynamoDBTable returns the ARN of a DynamoDB table.
+func (c Ctx) DynamoDBTable(name string) ARN {
+	return c.New("dynamodb", "table/", name)
+}
+
+// DynamoDBIndex returns the ARN of a DynamoDB table index.
+func (c Ctx) DynamoDBIndex(table, index string) ARN {
+	return c.New("dynamodb", "table/", table, "/index/", index)
+}
+
+// LogGroup returns the ARN of a CloudWatch Logs log group.
+func (c Ctx) LogGroup(name string) ARN {
+	return c.New("logs", "log-group:", name)
+}
+
+// iam returns the ARN of an IAM resource with a path.
+func (c Ctx) iam(typ, path, name string) ARN {
+	return c.New("iam", typ, string(cleanPath(path)), "/", name)
+}
@@ -69,3 +69,38 @@ func TestCtx(t *testing.T) {
 	assert.Panics(t, func() { arn.New("route53", "") })
 	assert.Equal(t, Ctx{"aws", "us-west-2", "123456789012"}, arn.In("us-west-2"))
 }
+
+func TestCtxBuilders(t *testing.T) {
+	c := Ctx{"aws", "us-east-1", "123456789012"}
+	tests := []*struct {
+		have ARN
+		want ARN
+	}{
+		{c.IAMGroup("", "g"), "arn:aws:iam::123456789012:group/g"},
+		{c.IAMInstanceProfile("a/b/", "p"),
+			"arn:aws:iam::123456789012:instance-profile/a/b/p"},
+		{c.IAMPolicy("/", "p"), "arn:aws:iam::123456789012:policy/p"},
+		{c.IAMRole("//a/./b", "r"), "arn:aws:iam::123456789012:role/a/b/r"},
+		{c.IAMUser("/a/", "u"), "arn:aws:iam::123456789012:user/a/u"},
+		{c.S3Bucket("b"), "arn:aws:s3:::b"},
+		{c.S3Object("b", "k/*"), "arn:aws:s3:::b/k/*"},
+		{c.LambdaFunction("f", ""),
+			"arn:aws:lambda:us-east-1:123456789012:function:f"},
+		{c.LambdaFunction("f", "$LATEST"),
+			"arn:aws:lambda:us-east-1:123456789012:function:f:$LATEST"},
+		{c.SQSQueue("q"), "arn:aws:sqs:us-east-1:123456789012:q"},
+		{c.SNSTopic("t"), "arn:aws:sns:us-east-1:123456789012:t"},
+		{c.KMSKey("1234abcd"), "arn:aws:kms:us-east-1:123456789012:key/1234abcd"},
+		{c.KMSAlias("a"), "arn:aws:kms:us-east-1:123456789012:alias/a"},
+		{c.KMSAlias("alias/a"), "arn:aws:kms:us-east-1:123456789012:alias/a"},
+		{c.DynamoDBTable("t"), "arn:aws:dynamodb:us-east-1:123456789012:table/t"},
+		{c.DynamoDBIndex("t", "i"),
+			"arn:aws:dynamodb:us-east-1:123456789012:table/t/index/i"},
+		{c.LogGroup("/aws/lambda/f"),
+			"arn:aws:logs:us-east-1:123456789012:log-group:/aws/lambda/f"},
+	}
+	for _, tc := range tests {
+		assert.Equal(t, tc.want, tc.have)
+		assert.NoError(t, tc.have.Validate(), "%s", tc.have)
+	}
+}
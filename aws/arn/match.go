@@ -0,0 +1,94 @@
+package arn
+
+import "strings"
+
+// Match returns true if r matches pattern p using IAM wildcard rules. A '*'
+// matches any sequence of characters and '?' matches any single character.
+// Wildcards in the partition, service, region, and account fields do not match
+// across the ':' field separator, but those in the resource field may match
+// both ':' and '/'. A pattern of "*" matches any ARN. Invalid ARNs and patterns
+// never match.
+func (r ARN) Match(p ARN) bool {
+	if p == "*" {
+		return true
+	}
+	rf, ok := r.split()
+	if !ok {
+		return false
+	}
+	pf, ok := p.split()
+	if !ok {
+		return false
+	}
+	for i := range rf {
+		if !Glob(pf[i], rf[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// Glob returns true if s matches pattern p, where '*' matches any sequence of
+// characters (including none) and '?' matches exactly one character. The IAM
+// policy variables "${*}", "${?}", and "${$}" match literal '*', '?', and '$'
+// characters. Matching is case-sensitive.
+func Glob(p, s string) bool {
+	if p == "*" {
+		return true
+	}
+	i, j, star, next := 0, 0, -1, 0
+	for j < len(s) {
+		if i < len(p) {
+			switch c := p[i]; {
+			case c == '*':
+				star, next = i, j
+				i++
+				continue
+			case c == '$' && isEscape(p[i:]):
+				if p[i+2] == s[j] {
+					i += len("${*}")
+					j++
+					continue
+				}
+			case c == '?' || c == s[j]:
+				i++
+				j++
+				continue
+			}
+		}
+		if star < 0 {
+			return false
+		}
+		next++
+		i, j = star+1, next
+	}
+	for i < len(p) && p[i] == '*' {
+		i++
+	}
+	return i == len(p)
+}
+
+// isEscape returns true if p begins with a "${*}", "${?}", or "${$}" literal
+// character escape.
+func isEscape(p string) bool {
+	return len(p) >= 4 && p[1] == '{' && p[3] == '}' &&
+		(p[2] == '*' || p[2] == '?' || p[2] == '$')
+}
+
+// split returns all ARN fields without panicking on invalid input.
+func (r ARN) split() ([fields]string, bool) {
+	var f [fields]string
+	if !strings.HasPrefix(string(r), string(prefix)) {
+		return f, false
+	}
+	s := string(r[len(prefix):])
+	for i := 0; i < fields-1; i++ {
+		j := strings.IndexByte(s, ':')
+		if j == -1 {
+			return f, false
+		}
+		f[i], s = s[:j], s[j+1:]
+	}
+	f[fields-1] = s
+	return f, true
+}
@@ -0,0 +1,71 @@
+package arn
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatch(t *testing.T) {
+	tests := []*struct {
+		pat  ARN
+		arn  ARN
+		want bool
+	}{
+		{"*", "", true},
+		{"*", "arn:aws:s3:::b", true},
+		{"arn:aws:s3:::my-bucket/*", "arn:aws:s3:::my-bucket/a/b:c", true},
+		{"arn:aws:s3:::my-bucket/*", "arn:aws:s3:::my-bucket", false},
+		{"arn:aws:s3:::my-bucket*", "arn:aws:s3:::my-bucket/x", true},
+		{"arn:aws:iam::*:role/app-?", "arn:aws:iam::123456789012:role/app-1", true},
+		{"arn:aws:iam::*:role/app-?", "arn:aws:iam::123456789012:role/app-10", false},
+		{"arn:aws:iam::*:role/*", "arn:aws:iam::123456789012:user/x", false},
+		{"arn:aws:*:*:*:*", "arn:aws:sns:us-east-1:123456789012:topic", true},
+		{"arn:aws:*", "arn:aws:sns:us-east-1:123456789012:topic", false},
+		{"arn:*:sqs:*", "arn:aws:sqs:us-east-1:123456789012:q", false},
+		{"arn:aws:sqs:us-*-1:*:q", "arn:aws:sqs:us-east-1:123456789012:q", true},
+		{"arn:aws:sqs:us-*:q", "arn:aws:sqs:us-east-1:123456789012:q", false},
+		{"arn:aws:ec2:*:*:instance/*", "arn:aws:ec2:us-east-1:1:instance/i-1", true},
+		{"arn:aws:ec2:*:*:instance/*", "arn:aws:EC2:us-east-1:1:instance/i-1", false},
+		{"arn:aws:s3:::b", "arn:aws:s3:::b", true},
+		{"arn:aws:s3:::b", "x", false},
+		{"x", "arn:aws:s3:::b", false},
+		{"", "", false},
+	}
+	for _, tc := range tests {
+		assert.Equal(t, tc.want, tc.arn.Match(tc.pat), "%+v", tc)
+	}
+}
+
+func TestGlob(t *testing.T) {
+	tests := []*struct {
+		pat, s string
+		want   bool
+	}{
+		{"", "", true},
+		{"*", "", true},
+		{"*", "abc", true},
+		{"?", "", false},
+		{"?", "a", true},
+		{"a*c", "abbbc", true},
+		{"a*c", "abbbd", false},
+		{"a*b*c", "axbxbxc", true},
+		{"a?c", "abc", true},
+		{"a?c", "ac", false},
+		{"**a", "bba", true},
+		{"a*", "b", false},
+		{"abc", "ABC", false},
+		{"a/*", "a/b:c/d", true},
+		{"${*}", "*", true},
+		{"${*}", "a", false},
+		{"${*}", "${*}", false},
+		{"a${?}*", "a?bc", true},
+		{"a${?}", "ab", false},
+		{"*${$}", "ab$", true},
+		{"${x}", "${x}", true},
+		{"${", "${", true},
+	}
+	for _, tc := range tests {
+		assert.Equal(t, tc.want, Glob(tc.pat, tc.s), "%+v", tc)
+	}
+}
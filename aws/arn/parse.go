@@ -0,0 +1,143 @@
+package arn
+
+import (
+	"strconv"
+	"strings"
+)
+
+// ARN components reported by Error.
+const (
+	CompPrefix    = "prefix"
+	CompPartition = "partition"
+	CompService   = "service"
+	CompRegion    = "region"
+	CompAccount   = "account"
+	CompResource  = "resource"
+)
+
+// Error describes an invalid ARN component.
+type Error struct {
+	ARN       string
+	Component string
+	Reason    string
+}
+
+// Error implements error interface.
+func (e *Error) Error() string {
+	return "arn: invalid " + e.Component + " in " + strconv.Quote(e.ARN) +
+		" (" + e.Reason + ")"
+}
+
+// Components contains parsed ARN fields. Resource is the complete resource
+// field. It is also split into type, path, and name, following the same rules
+// as ARN Type, Path, and Name methods. This split is lossy for resources that
+// contain both '/' and ':' separators, such as log groups, so the ARN must be
+// reconstructed from Resource rather than from the split fields.
+type Components struct {
+	Partition    string
+	Service      string
+	Region       string
+	Account      string
+	Resource     string
+	ResourceType string
+	ResourcePath string
+	ResourceName string
+}
+
+// Parse validates s and returns it as an ARN. Partition, service, and resource
+// fields are required. Region, if present, must contain only lowercase letters,
+// digits, and hyphens. Account, if present, must be a 12-digit ID or "aws". The
+// returned error is always of type *Error.
+func Parse(s string) (ARN, error) {
+	if !strings.HasPrefix(s, string(prefix)) {
+		return "", &Error{s, CompPrefix, `must begin with "arn:"`}
+	}
+	f := strings.SplitN(s[len(prefix):], ":", fields)
+	for i, v := range f {
+		if reason := checkComp(i, v); reason != "" {
+			return "", &Error{s, compNames[i], reason}
+		}
+	}
+	if len(f) < fields {
+		return "", &Error{s, compNames[len(f)], "missing"}
+	}
+	return ARN(s), nil
+}
+
+// Components parses r and returns its components.
+func (r ARN) Components() (Components, error) {
+	if _, err := Parse(string(r)); err != nil {
+		return Components{}, err
+	}
+	return Components{
+		Partition:    r.Partition(),
+		Service:      r.Service(),
+		Region:       r.Region(),
+		Account:      r.Account(),
+		Resource:     r.Resource(),
+		ResourceType: r.Type(),
+		ResourcePath: r.Path(),
+		ResourceName: r.Name(),
+	}, nil
+}
+
+// ARN returns the ARN described by c.
+func (c *Components) ARN() ARN {
+	return New(c.Partition, c.Service, c.Region, c.Account, c.Resource)
+}
+
+// compNames maps field indices to component names.
+var compNames = [fields]string{
+	CompPartition,
+	CompService,
+	CompRegion,
+	CompAccount,
+	CompResource,
+}
+
+// checkComp validates the value of the ith field, returning a non-empty reason
+// if the value is invalid.
+func checkComp(i int, v string) string {
+	switch i {
+	case 0, 1:
+		if v == "" {
+			return "missing"
+		}
+		if !isLowerAlnum(v) {
+			return "must contain only a-z, 0-9, and '-'"
+		}
+	case 2:
+		if !isLowerAlnum(v) {
+			return "must contain only a-z, 0-9, and '-'"
+		}
+	case 3:
+		if v == "" || v == "aws" {
+			break
+		}
+		if len(v) != 12 {
+			return "must be a 12-digit account ID"
+		}
+		for i := 0; i < len(v); i++ {
+			if v[i] < '0' || '9' < v[i] {
+				return "must be a 12-digit account ID"
+			}
+		}
+	case 4:
+		if v == "" {
+			return "missing"
+		}
+	}
+	return ""
+}
+
+// isLowerAlnum returns true if s contains only lowercase letters, digits, and
+// hyphens.
+func isLowerAlnum(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if c := s[i]; !('a' <= c && c <= 'z' || '0' <= c && c <= '9' ||
+			c == '-') {
+			return false
+		}
+	}
+	return true
+}
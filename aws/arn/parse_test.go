@@ -0,0 +1,96 @@
+package arn
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	tests := []*struct {
+		in   string
+		want Components
+	}{{
+		in: "arn:aws:s3:::my-bucket",
+		want: Components{
+			Partition:    "aws",
+			Service:      "s3",
+			Resource:     "my-bucket",
+			ResourceName: "my-bucket",
+		},
+	}, {
+		in: "arn:aws:iam::123456789012:role/app/x/name",
+		want: Components{
+			Partition:    "aws",
+			Service:      "iam",
+			Account:      "123456789012",
+			Resource:     "role/app/x/name",
+			ResourceType: "role",
+			ResourcePath: "/app/x/",
+			ResourceName: "name",
+		},
+	}, {
+		in: "arn:aws-us-gov:iam::aws:policy/AdministratorAccess",
+		want: Components{
+			Partition:    "aws-us-gov",
+			Service:      "iam",
+			Account:      "aws",
+			Resource:     "policy/AdministratorAccess",
+			ResourceType: "policy",
+			ResourcePath: "/",
+			ResourceName: "AdministratorAccess",
+		},
+	}, {
+		in: "arn:aws:logs:us-east-1:123456789012:log-group:/aws/lambda/f:*",
+		want: Components{
+			Partition:    "aws",
+			Service:      "logs",
+			Region:       "us-east-1",
+			Account:      "123456789012",
+			Resource:     "log-group:/aws/lambda/f:*",
+			ResourceType: "log-group",
+			ResourceName: "*",
+		},
+	}}
+	for _, tc := range tests {
+		r, err := Parse(tc.in)
+		require.NoError(t, err, "%s", tc.in)
+		assert.Equal(t, ARN(tc.in), r)
+		c, err := r.Components()
+		require.NoError(t, err, "%s", tc.in)
+		assert.Equal(t, tc.want, c, "%s", tc.in)
+		assert.Equal(t, r, c.ARN(), "%s", tc.in)
+	}
+}
+
+func TestParseError(t *testing.T) {
+	tests := []*struct{ in, comp string }{
+		{"", CompPrefix},
+		{"ARN:aws:s3:::b", CompPrefix},
+		{"arn", CompPrefix},
+		{"arn:", CompPartition},
+		{"arn:aws", CompService},
+		{"arn:aws:", CompService},
+		{"arn:AWS:s3:::b", CompPartition},
+		{"arn:aws:s3", CompRegion},
+		{"arn:aws:s3:us_east_1:", CompRegion},
+		{"arn:aws:s3::", CompResource},
+		{"arn:aws:s3:::", CompResource},
+		{"arn:aws:iam::12345:role/x", CompAccount},
+		{"arn:aws:iam::12345678901x:role/x", CompAccount},
+		{"arn:aws:iam::*:role/x", CompAccount},
+	}
+	for _, tc := range tests {
+		r, err := Parse(tc.in)
+		assert.Equal(t, ARN(""), r, "%s", tc.in)
+		if assert.IsType(t, (*Error)(nil), err, "%s", tc.in) {
+			e := err.(*Error)
+			assert.Equal(t, tc.in, e.ARN)
+			assert.Equal(t, tc.comp, e.Component, "%s", tc.in)
+		}
+		assert.NotPanics(t, func() { ARN(tc.in).Components() })
+	}
+	_, err := ARN("x").Components()
+	assert.EqualError(t, err, `arn: invalid prefix in "x" (must begin with "arn:")`)
+}
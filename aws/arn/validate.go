@@ -0,0 +1,201 @@
+package arn
+
+import "strconv"
+
+// Validate checks that r is a syntactically valid ARN (see Parse) and, for
+// known services, that its region, account, and resource fields match the
+// service resource format. Services that are not known to the validator are
+// only checked for syntax. The returned error is always of type *Error.
+func (r ARN) Validate() error {
+	if _, err := Parse(string(r)); err != nil {
+		return err
+	}
+	svc := services[r.Service()]
+	if svc == nil {
+		return nil
+	}
+	res := r.Resource()
+	var spec *resSpec
+	for i := range svc.res {
+		if Glob(svc.res[i].pat, res) {
+			spec = &svc.res[i]
+			break
+		}
+	}
+	if spec == nil {
+		reason := "unknown " + r.Service() + " resource type"
+		if typ := r.Type(); typ != "" {
+			for i := range svc.res {
+				if p := svc.res[i].pat; len(p) > len(typ) &&
+					p[:len(typ)] == typ && (p[len(typ)] == '/' ||
+					p[len(typ)] == ':') {
+					reason = "invalid " + typ + " format"
+					break
+				}
+			}
+			reason += " " + strconv.Quote(typ)
+		}
+		return &Error{string(r), CompResource, reason}
+	}
+	region, account := spec.region, spec.account
+	if region == inherit {
+		region = svc.region
+	}
+	if account == inherit {
+		account = svc.account
+	}
+	if reason := region.check(r.Region()); reason != "" {
+		return &Error{string(r), CompRegion, reason}
+	}
+	acct := r.Account()
+	if reason := account.check(acct); reason != "" {
+		return &Error{string(r), CompAccount, reason}
+	}
+	if acct == "aws" && !spec.aws {
+		return &Error{string(r), CompAccount, `"aws" not allowed`}
+	}
+	return nil
+}
+
+// rule specifies whether an ARN field must or must not be set.
+type rule uint8
+
+// Field rules. Resource-level inherit uses the service-level rule.
+const (
+	inherit rule = iota
+	required
+	forbidden
+)
+
+// check returns a non-empty reason if field value v does not satisfy rule r.
+func (r rule) check(v string) string {
+	switch {
+	case r == required && v == "":
+		return "missing"
+	case r == forbidden && v != "":
+		return "not allowed"
+	}
+	return ""
+}
+
+// svcSpec describes the ARN format of one service.
+type svcSpec struct {
+	region  rule
+	account rule
+	res     []resSpec
+}
+
+// resSpec describes the format of one resource type. The resource field must
+// match pat using Glob rules. Region and account rules override those of the
+// service unless set to inherit. If aws is true, "aws" is an allowed account.
+type resSpec struct {
+	pat     string
+	region  rule
+	account rule
+	aws     bool
+}
+
+// services contains ARN formats of known services. The region and account
+// rules must remain consistent with Ctx.New.
+var services = map[string]*svcSpec{
+	"apigateway": {required, forbidden, []resSpec{{pat: "/?*"}}},
+	"artifact": {forbidden, forbidden, []resSpec{
+		{pat: "report-package/?*"},
+	}},
+	"cloudfront": {forbidden, required, []resSpec{
+		{pat: "distribution/?*"},
+		{pat: "origin-access-identity/?*"},
+		{pat: "streaming-distribution/?*"},
+	}},
+	"cloudwatch": {required, required, []resSpec{
+		{pat: "alarm:?*"},
+		{pat: "dashboard/?*", region: forbidden},
+	}},
+	"dynamodb": {required, required, []resSpec{
+		{pat: "global-table/?*", region: forbidden},
+		{pat: "table/?*"},
+	}},
+	"ec2": {required, required, []resSpec{
+		{pat: "capacity-reservation/cr-?*"},
+		{pat: "customer-gateway/cgw-?*"},
+		{pat: "dedicated-host/h-?*"},
+		{pat: "dhcp-options/dopt-?*"},
+		{pat: "elastic-gpu/egpu-?*"},
+		{pat: "elastic-ip/eipalloc-?*"},
+		{pat: "fleet/fleet-?*"},
+		{pat: "image/ami-?*", account: forbidden},
+		{pat: "instance/i-?*"},
+		{pat: "internet-gateway/igw-?*"},
+		{pat: "key-pair/?*"},
+		{pat: "launch-template/lt-?*"},
+		{pat: "natgateway/nat-?*"},
+		{pat: "network-acl/acl-?*"},
+		{pat: "network-interface/eni-?*"},
+		{pat: "placement-group/?*"},
+		{pat: "reserved-instances/?*"},
+		{pat: "route-table/rtb-?*"},
+		{pat: "security-group/sg-?*"},
+		{pat: "snapshot/snap-?*", account: forbidden},
+		{pat: "spot-instances-request/sir-?*"},
+		{pat: "subnet/subnet-?*"},
+		{pat: "transit-gateway/tgw-?*"},
+		{pat: "volume/vol-?*"},
+		{pat: "vpc-endpoint/vpce-?*"},
+		{pat: "vpc-peering-connection/pcx-?*"},
+		{pat: "vpc/vpc-?*"},
+		{pat: "vpn-connection/vpn-?*"},
+		{pat: "vpn-gateway/vgw-?*"},
+	}},
+	"elasticbeanstalk": {required, required, []resSpec{
+		{pat: "application/?*"},
+		{pat: "applicationversion/?*/?*"},
+		{pat: "configurationtemplate/?*/?*"},
+		{pat: "environment/?*/?*"},
+		{pat: "platform/?*"},
+		{pat: "solutionstack/?*", account: forbidden},
+	}},
+	"health": {required, required, []resSpec{
+		{pat: "event/?*", account: forbidden},
+	}},
+	"iam": {forbidden, required, []resSpec{
+		{pat: "root"},
+		{pat: "group/?*"},
+		{pat: "instance-profile/?*"},
+		{pat: "mfa/?*"},
+		{pat: "oidc-provider/?*"},
+		{pat: "policy/?*", aws: true},
+		{pat: "role/?*"},
+		{pat: "saml-provider/?*"},
+		{pat: "server-certificate/?*"},
+		{pat: "user/?*"},
+	}},
+	"kms": {required, required, []resSpec{
+		{pat: "alias/?*"},
+		{pat: "key/?*"},
+	}},
+	"lambda": {required, required, []resSpec{
+		{pat: "event-source-mapping:?*"},
+		{pat: "function:?*"},
+		{pat: "layer:?*"},
+	}},
+	"logs": {required, required, []resSpec{
+		{pat: "destination:?*"},
+		{pat: "log-group:?*"},
+	}},
+	"route53": {forbidden, forbidden, []resSpec{
+		{pat: "change/?*"},
+		{pat: "delegationset/?*"},
+		{pat: "domain:?*", account: required},
+		{pat: "healthcheck/?*"},
+		{pat: "hostedzone/?*"},
+		{pat: "trafficpolicy/?*"},
+		{pat: "trafficpolicyinstance/?*"},
+	}},
+	"s3":  {forbidden, forbidden, []resSpec{{pat: "?*"}}},
+	"sns": {required, required, []resSpec{{pat: "?*"}}},
+	"sqs": {required, required, []resSpec{{pat: "?*"}}},
+	"sts": {forbidden, required, []resSpec{
+		{pat: "assumed-role/?*/?*"},
+		{pat: "federated-user/?*"},
+	}},
+}
@@ -0,0 +1,86 @@
+package arn
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidate(t *testing.T) {
+	valid := []ARN{
+		"arn:aws:iam::123456789012:role/app/name",
+		"arn:aws:iam::123456789012:root",
+		"arn:aws:iam::aws:policy/AdministratorAccess",
+		"arn:aws:s3:::my-bucket",
+		"arn:aws:s3:::my-bucket/key/with:colon",
+		"arn:aws:ec2:us-east-1:123456789012:instance/i-0123456789abcdef0",
+		"arn:aws:ec2:us-east-1::image/ami-1a2b3c4d",
+		"arn:aws:lambda:us-east-1:123456789012:function:f:1",
+		"arn:aws:dynamodb:us-east-1:123456789012:table/t/index/i",
+		"arn:aws:logs:us-east-1:123456789012:log-group:/aws/lambda/f:*",
+		"arn:aws:sqs:us-east-1:123456789012:queue",
+		"arn:aws:sts::123456789012:assumed-role/r/s",
+		"arn:aws:waf::123456789012:anything",
+		"arn:aws:unknown:x:123456789012:y",
+	}
+	for _, r := range valid {
+		assert.NoError(t, r.Validate(), "%s", r)
+	}
+	invalid := []*struct {
+		arn  ARN
+		comp string
+	}{
+		{"arn:aws:iam", CompRegion},
+		{"arn:aws:iam:us-east-1:123456789012:role/x", CompRegion},
+		{"arn:aws:iam:::role/x", CompAccount},
+		{"arn:aws:iam::aws:role/x", CompAccount},
+		{"arn:aws:iam::123456789012:role/", CompResource},
+		{"arn:aws:iam::123456789012:bucket/x", CompResource},
+		{"arn:aws:s3:us-east-1::b", CompRegion},
+		{"arn:aws:s3:::", CompResource},
+		{"arn:aws:s3::123456789012:b", CompAccount},
+		{"arn:aws:ec2::123456789012:instance/i-1", CompRegion},
+		{"arn:aws:ec2:us-east-1::instance/i-1", CompAccount},
+		{"arn:aws:ec2:us-east-1:123456789012:instance/x", CompResource},
+		{"arn:aws:ec2:us-east-1:123456789012:image/ami-1", CompAccount},
+		{"arn:aws:cloudwatch:us-east-1:123456789012:dashboard/d", CompRegion},
+		{"arn:aws:route53::123456789012:hostedzone/Z1", CompAccount},
+		{"arn:aws:route53:::domain:example.com", CompAccount},
+	}
+	for _, tc := range invalid {
+		err := tc.arn.Validate()
+		if assert.IsType(t, (*Error)(nil), err, "%s", tc.arn) {
+			assert.Equal(t, tc.comp, err.(*Error).Component, "%s", tc.arn)
+		}
+	}
+	err := ARN("arn:aws:iam::123456789012:bucket/x").Validate()
+	assert.EqualError(t, err, `arn: invalid resource in `+
+		`"arn:aws:iam::123456789012:bucket/x" (unknown iam resource type "bucket")`)
+	err = ARN("arn:aws:ec2:us-east-1:123456789012:instance/x").Validate()
+	assert.Contains(t, err.Error(), `(invalid instance format "instance")`)
+}
+
+func TestValidateCtx(t *testing.T) {
+	c := Ctx{"aws", "us-east-1", "123456789012"}
+	tests := []struct{ svc, res string }{
+		{"apigateway", "/restapis/a1/*"},
+		{"cloudfront", "distribution/E1"},
+		{"cloudwatch", "alarm:a"},
+		{"cloudwatch", "dashboard/d"},
+		{"ec2", "image/ami-1"},
+		{"ec2", "snapshot/snap-1"},
+		{"ec2", "volume/vol-1"},
+		{"elasticbeanstalk", "solutionstack/x"},
+		{"health", "event/x"},
+		{"iam", "role/x"},
+		{"route53", "change/C1"},
+		{"route53", "domain:example.com"},
+		{"route53", "hostedzone/Z1"},
+		{"s3", "b"},
+		{"sts", "federated-user/u"},
+	}
+	for _, tc := range tests {
+		r := c.New(tc.svc, tc.res)
+		assert.NoError(t, r.Validate(), "%s", r)
+	}
+}
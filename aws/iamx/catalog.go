@@ -0,0 +1,251 @@
+package iamx
+
+import (
+	"strings"
+	"sync"
+)
+
+//go:generate go run gen_catalog.go -o catalog_data.go -complete sqs catalog.json
+
+// AccessLevel classifies IAM actions by the type of access that they grant.
+type AccessLevel uint8
+
+// IAM action access levels.
+const (
+	AccessList AccessLevel = iota + 1
+	AccessRead
+	AccessWrite
+	AccessPermissions
+	AccessTagging
+)
+
+// String implements fmt.Stringer.
+func (a AccessLevel) String() string {
+	switch a {
+	case AccessList:
+		return "List"
+	case AccessRead:
+		return "Read"
+	case AccessWrite:
+		return "Write"
+	case AccessPermissions:
+		return "Permissions management"
+	case AccessTagging:
+		return "Tagging"
+	}
+	return "Unknown"
+}
+
+// ActionInfo describes an IAM service action from the embedded catalog.
+// Resources lists the resource types that the action applies to, and
+// Conditions lists action-specific condition keys.
+type ActionInfo struct {
+	Service    string
+	Name       string
+	Access     AccessLevel
+	Resources  []string
+	Conditions []string
+}
+
+// String returns the full action name (e.g. "s3:GetObject").
+func (a *ActionInfo) String() string { return a.Service + ":" + a.Name }
+
+var (
+	catalogOnce sync.Once
+	catalogIdx  map[string]*ActionInfo
+	catalogSvc  map[string][]*ActionInfo
+)
+
+// loadCatalog indexes catalog entries by lower case name and service.
+func loadCatalog() {
+	catalogIdx = make(map[string]*ActionInfo, len(catalog))
+	catalogSvc = make(map[string][]*ActionInfo)
+	for i := range catalog {
+		a := &catalog[i]
+		catalogIdx[strings.ToLower(a.String())] = a
+		catalogSvc[a.Service] = append(catalogSvc[a.Service], a)
+	}
+}
+
+// LookupAction returns catalog information for the specified action, which is
+// matched without regard to case. It returns nil if the action is unknown.
+func LookupAction(action string) *ActionInfo {
+	catalogOnce.Do(loadCatalog)
+	return catalogIdx[strings.ToLower(action)]
+}
+
+// MatchActions returns all catalog actions that match action pattern pat, in
+// catalog order. Matching is case-insensitive.
+func MatchActions(pat string) []*ActionInfo {
+	catalogOnce.Do(loadCatalog)
+	if !strings.ContainsAny(pat, "*?") {
+		if a := catalogIdx[strings.ToLower(pat)]; a != nil {
+			return []*ActionInfo{a}
+		}
+		return nil
+	}
+	var all []*ActionInfo
+	for i := range catalog {
+		if a := &catalog[i]; wildcardMatch(pat, a.String(), true) {
+			all = append(all, a)
+		}
+	}
+	return all
+}
+
+// ExpandActions returns a copy of statement s with all Action and NotAction
+// patterns replaced by the catalog actions that they match. Known actions are
+// converted to their canonical names. The "*" pattern, unknown actions, and
+// patterns that do not match any catalog actions are kept as is. Since the
+// catalog may not contain every action, the expanded statement could grant
+// fewer permissions than the original.
+func (s *Statement) ExpandActions() *Statement {
+	c := s.Clone()
+	c.Action = expandActions(c.Action)
+	c.NotAction = expandActions(c.NotAction)
+	return c
+}
+
+// CompressActions returns a copy of statement s with the smallest set of
+// Action and NotAction patterns that match the same actions. Only actions of
+// services that are marked as complete when the catalog is generated (see the
+// -complete flag of gen_catalog.go) are compressed, and a wildcard is used only
+// if every action that it matches was already matched by the original
+// statement. Actions and patterns of all other services are kept
+// as is, with known action names converted to their canonical form, so the
+// result never matches more actions than the original.
+func (s *Statement) CompressActions() *Statement {
+	c := s.Clone()
+	c.Action = compressActions(c.Action)
+	c.NotAction = compressActions(c.NotAction)
+	return c
+}
+
+// expandActions expands all patterns in v. The result is sorted and
+// deduplicated.
+func expandActions(v PolicyMultiVal) PolicyMultiVal {
+	if v == nil {
+		return nil
+	}
+	out := make(PolicyMultiVal, 0, len(v))
+	for _, pat := range v {
+		m := MatchActions(pat)
+		if pat == "*" || len(m) == 0 {
+			out = append(out, pat)
+			continue
+		}
+		for _, a := range m {
+			out = append(out, a.String())
+		}
+	}
+	return out.normalize()
+}
+
+// compressActions replaces catalog actions in v with the smallest set of
+// patterns matching the same actions. The result is sorted and deduplicated.
+func compressActions(v PolicyMultiVal) PolicyMultiVal {
+	if v == nil {
+		return nil
+	}
+	if v.has("*") {
+		return PolicyMultiVal{"*"}
+	}
+	var in, out PolicyMultiVal
+	for _, pat := range v {
+		i := strings.IndexByte(pat, ':')
+		if i > 0 && completeServices[strings.ToLower(pat[:i])] {
+			in = append(in, pat)
+		} else if a := LookupAction(pat); a != nil {
+			out = append(out, a.String())
+		} else {
+			out = append(out, pat)
+		}
+	}
+	sets := make(map[string]map[*ActionInfo]bool)
+	for _, name := range expandActions(in) {
+		a := LookupAction(name)
+		if a == nil {
+			out = append(out, name)
+			continue
+		}
+		if sets[a.Service] == nil {
+			sets[a.Service] = make(map[*ActionInfo]bool)
+		}
+		sets[a.Service][a] = true
+	}
+	for svc, set := range sets {
+		all := catalogSvc[svc]
+		if len(set) == len(all) {
+			out = append(out, svc+":*")
+			continue
+		}
+		for _, a := range all {
+			if set[a] {
+				out = append(out, svc+":"+minPattern(a.Name, all, set))
+			}
+		}
+	}
+	return out.normalizeActions()
+}
+
+// minPattern returns the most general pattern for action name that does not
+// match any actions in all that are not in set. The shortest prefix is used to
+// minimize the number of patterns, and it is then extended to the longest
+// word boundary that matches the same actions. The action name is returned if
+// no wildcard pattern matches more than one action.
+func minPattern(name string, all []*ActionInfo,
+	set map[*ActionInfo]bool) string {
+	lname := strings.ToLower(name)
+	for k := 1; k < len(name); k++ {
+		var m []string
+		for _, a := range all {
+			if strings.HasPrefix(strings.ToLower(a.Name), lname[:k]) {
+				if !set[a] {
+					m = nil
+					break
+				}
+				m = append(m, a.Name)
+			}
+		}
+		if len(m) == 0 {
+			continue
+		}
+		if len(m) == 1 {
+			return name
+		}
+		lcp := len(m[0])
+		for _, s := range m[1:] {
+			i := 0
+			for i < lcp && i < len(s) && lower(s[i]) == lower(m[0][i]) {
+				i++
+			}
+			lcp = i
+		}
+		for j := lcp; j >= k; j-- {
+			if wordBoundary(m, j) {
+				return name[:j] + "*"
+			}
+		}
+		return name[:lcp] + "*"
+	}
+	return name
+}
+
+// lower returns the lower case version of ASCII character c.
+func lower(c byte) byte {
+	if 'A' <= c && c <= 'Z' {
+		c += 'a' - 'A'
+	}
+	return c
+}
+
+// wordBoundary returns true if every name in m ends or has an upper case
+// letter at index i.
+func wordBoundary(m []string, i int) bool {
+	for _, s := range m {
+		if i < len(s) && !('A' <= s[i] && s[i] <= 'Z') {
+			return false
+		}
+	}
+	return true
+}
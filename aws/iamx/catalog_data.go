@@ -0,0 +1,334 @@
+// Code generated by gen_catalog.go; DO NOT EDIT.
+
+package iamx
+
+var catalog = [...]ActionInfo{
+	{"dynamodb", "BatchGetItem", AccessRead, []string{"table"}, []string{"dynamodb:Attributes", "dynamodb:LeadingKeys", "dynamodb:ReturnConsumedCapacity", "dynamodb:Select"}},
+	{"dynamodb", "BatchWriteItem", AccessWrite, []string{"table"}, []string{"dynamodb:Attributes", "dynamodb:LeadingKeys", "dynamodb:ReturnConsumedCapacity"}},
+	{"dynamodb", "ConditionCheckItem", AccessRead, []string{"table"}, []string{"dynamodb:Attributes", "dynamodb:LeadingKeys", "dynamodb:ReturnConsumedCapacity", "dynamodb:ReturnValues"}},
+	{"dynamodb", "CreateBackup", AccessWrite, []string{"table"}, nil},
+	{"dynamodb", "CreateTable", AccessWrite, []string{"table"}, nil},
+	{"dynamodb", "DeleteBackup", AccessWrite, []string{"backup"}, nil},
+	{"dynamodb", "DeleteItem", AccessWrite, []string{"table"}, []string{"dynamodb:Attributes", "dynamodb:EnclosingOperation", "dynamodb:LeadingKeys", "dynamodb:ReturnConsumedCapacity", "dynamodb:ReturnValues"}},
+	{"dynamodb", "DeleteTable", AccessWrite, []string{"table"}, nil},
+	{"dynamodb", "DescribeBackup", AccessRead, []string{"backup"}, nil},
+	{"dynamodb", "DescribeStream", AccessRead, []string{"stream"}, nil},
+	{"dynamodb", "DescribeTable", AccessRead, []string{"table"}, nil},
+	{"dynamodb", "DescribeTimeToLive", AccessRead, []string{"table"}, nil},
+	{"dynamodb", "GetItem", AccessRead, []string{"table"}, []string{"dynamodb:Attributes", "dynamodb:LeadingKeys", "dynamodb:ReturnConsumedCapacity", "dynamodb:Select"}},
+	{"dynamodb", "GetRecords", AccessRead, []string{"stream"}, nil},
+	{"dynamodb", "GetShardIterator", AccessRead, []string{"stream"}, nil},
+	{"dynamodb", "ListBackups", AccessList, nil, nil},
+	{"dynamodb", "ListStreams", AccessRead, nil, nil},
+	{"dynamodb", "ListTables", AccessList, nil, nil},
+	{"dynamodb", "ListTagsOfResource", AccessRead, []string{"table"}, nil},
+	{"dynamodb", "PutItem", AccessWrite, []string{"table"}, []string{"dynamodb:Attributes", "dynamodb:EnclosingOperation", "dynamodb:LeadingKeys", "dynamodb:ReturnConsumedCapacity", "dynamodb:ReturnValues"}},
+	{"dynamodb", "Query", AccessRead, []string{"index", "table"}, []string{"dynamodb:Attributes", "dynamodb:LeadingKeys", "dynamodb:ReturnConsumedCapacity", "dynamodb:Select"}},
+	{"dynamodb", "RestoreTableFromBackup", AccessWrite, []string{"backup", "table"}, nil},
+	{"dynamodb", "Scan", AccessRead, []string{"index", "table"}, []string{"dynamodb:Attributes", "dynamodb:ReturnConsumedCapacity", "dynamodb:Select"}},
+	{"dynamodb", "TagResource", AccessTagging, []string{"table"}, []string{"aws:RequestTag/${TagKey}", "aws:TagKeys"}},
+	{"dynamodb", "UntagResource", AccessTagging, []string{"table"}, []string{"aws:TagKeys"}},
+	{"dynamodb", "UpdateItem", AccessWrite, []string{"table"}, []string{"dynamodb:Attributes", "dynamodb:EnclosingOperation", "dynamodb:LeadingKeys", "dynamodb:ReturnConsumedCapacity", "dynamodb:ReturnValues"}},
+	{"dynamodb", "UpdateTable", AccessWrite, []string{"table"}, nil},
+	{"dynamodb", "UpdateTimeToLive", AccessWrite, []string{"table"}, nil},
+	{"ec2", "AttachVolume", AccessWrite, []string{"instance", "volume"}, []string{"ec2:InstanceType", "ec2:ResourceTag/${TagKey}", "ec2:VolumeType"}},
+	{"ec2", "AuthorizeSecurityGroupEgress", AccessWrite, []string{"security-group"}, []string{"ec2:ResourceTag/${TagKey}", "ec2:Vpc"}},
+	{"ec2", "AuthorizeSecurityGroupIngress", AccessWrite, []string{"security-group"}, []string{"ec2:ResourceTag/${TagKey}", "ec2:Vpc"}},
+	{"ec2", "CreateSecurityGroup", AccessWrite, []string{"security-group", "vpc"}, []string{"aws:RequestTag/${TagKey}", "aws:TagKeys", "ec2:Vpc"}},
+	{"ec2", "CreateSnapshot", AccessWrite, []string{"snapshot", "volume"}, []string{"aws:RequestTag/${TagKey}", "aws:TagKeys", "ec2:VolumeType"}},
+	{"ec2", "CreateTags", AccessTagging, []string{"instance", "security-group", "snapshot", "subnet", "volume", "vpc"}, []string{"aws:RequestTag/${TagKey}", "aws:TagKeys", "ec2:CreateAction"}},
+	{"ec2", "CreateVolume", AccessWrite, []string{"volume"}, []string{"aws:RequestTag/${TagKey}", "aws:TagKeys", "ec2:Encrypted", "ec2:VolumeSize", "ec2:VolumeType"}},
+	{"ec2", "DeleteSecurityGroup", AccessWrite, []string{"security-group"}, []string{"ec2:ResourceTag/${TagKey}", "ec2:Vpc"}},
+	{"ec2", "DeleteSnapshot", AccessWrite, []string{"snapshot"}, []string{"ec2:ResourceTag/${TagKey}"}},
+	{"ec2", "DeleteTags", AccessTagging, []string{"instance", "security-group", "snapshot", "subnet", "volume", "vpc"}, []string{"aws:RequestTag/${TagKey}", "aws:TagKeys"}},
+	{"ec2", "DeleteVolume", AccessWrite, []string{"volume"}, []string{"ec2:ResourceTag/${TagKey}"}},
+	{"ec2", "DescribeImages", AccessList, nil, nil},
+	{"ec2", "DescribeInstances", AccessList, nil, nil},
+	{"ec2", "DescribeRegions", AccessList, nil, nil},
+	{"ec2", "DescribeSecurityGroups", AccessList, nil, nil},
+	{"ec2", "DescribeSnapshots", AccessList, nil, nil},
+	{"ec2", "DescribeSubnets", AccessList, nil, nil},
+	{"ec2", "DescribeTags", AccessList, nil, nil},
+	{"ec2", "DescribeVolumes", AccessList, nil, nil},
+	{"ec2", "DescribeVpcs", AccessList, nil, nil},
+	{"ec2", "DetachVolume", AccessWrite, []string{"instance", "volume"}, []string{"ec2:ResourceTag/${TagKey}"}},
+	{"ec2", "GetConsoleOutput", AccessRead, []string{"instance"}, []string{"ec2:ResourceTag/${TagKey}"}},
+	{"ec2", "RebootInstances", AccessWrite, []string{"instance"}, []string{"ec2:InstanceType", "ec2:ResourceTag/${TagKey}"}},
+	{"ec2", "RevokeSecurityGroupEgress", AccessWrite, []string{"security-group"}, []string{"ec2:ResourceTag/${TagKey}", "ec2:Vpc"}},
+	{"ec2", "RevokeSecurityGroupIngress", AccessWrite, []string{"security-group"}, []string{"ec2:ResourceTag/${TagKey}", "ec2:Vpc"}},
+	{"ec2", "RunInstances", AccessWrite, []string{"image", "instance", "network-interface", "security-group", "subnet", "volume"}, []string{"aws:RequestTag/${TagKey}", "aws:TagKeys", "ec2:InstanceProfile", "ec2:InstanceType", "ec2:Vpc"}},
+	{"ec2", "StartInstances", AccessWrite, []string{"instance"}, []string{"ec2:InstanceType", "ec2:ResourceTag/${TagKey}"}},
+	{"ec2", "StopInstances", AccessWrite, []string{"instance"}, []string{"ec2:InstanceType", "ec2:ResourceTag/${TagKey}"}},
+	{"ec2", "TerminateInstances", AccessWrite, []string{"instance"}, []string{"ec2:InstanceType", "ec2:ResourceTag/${TagKey}"}},
+	{"iam", "AddRoleToInstanceProfile", AccessWrite, []string{"instance-profile"}, nil},
+	{"iam", "AddUserToGroup", AccessWrite, []string{"group"}, nil},
+	{"iam", "AttachGroupPolicy", AccessPermissions, []string{"group"}, []string{"iam:PolicyARN"}},
+	{"iam", "AttachRolePolicy", AccessPermissions, []string{"role"}, []string{"iam:PermissionsBoundary", "iam:PolicyARN"}},
+	{"iam", "AttachUserPolicy", AccessPermissions, []string{"user"}, []string{"iam:PermissionsBoundary", "iam:PolicyARN"}},
+	{"iam", "CreateAccessKey", AccessWrite, []string{"user"}, nil},
+	{"iam", "CreateGroup", AccessWrite, []string{"group"}, nil},
+	{"iam", "CreateInstanceProfile", AccessWrite, []string{"instance-profile"}, nil},
+	{"iam", "CreateLoginProfile", AccessWrite, []string{"user"}, nil},
+	{"iam", "CreatePolicy", AccessPermissions, []string{"policy"}, nil},
+	{"iam", "CreatePolicyVersion", AccessPermissions, []string{"policy"}, nil},
+	{"iam", "CreateRole", AccessWrite, []string{"role"}, []string{"aws:RequestTag/${TagKey}", "aws:TagKeys", "iam:PermissionsBoundary"}},
+	{"iam", "CreateServiceLinkedRole", AccessWrite, []string{"role"}, []string{"iam:AWSServiceName"}},
+	{"iam", "CreateUser", AccessWrite, []string{"user"}, []string{"aws:RequestTag/${TagKey}", "aws:TagKeys", "iam:PermissionsBoundary"}},
+	{"iam", "CreateVirtualMFADevice", AccessWrite, []string{"mfa"}, nil},
+	{"iam", "DeactivateMFADevice", AccessWrite, []string{"user"}, nil},
+	{"iam", "DeleteAccessKey", AccessWrite, []string{"user"}, nil},
+	{"iam", "DeleteGroup", AccessWrite, []string{"group"}, nil},
+	{"iam", "DeleteGroupPolicy", AccessPermissions, []string{"group"}, nil},
+	{"iam", "DeleteInstanceProfile", AccessWrite, []string{"instance-profile"}, nil},
+	{"iam", "DeleteLoginProfile", AccessWrite, []string{"user"}, nil},
+	{"iam", "DeletePolicy", AccessPermissions, []string{"policy"}, nil},
+	{"iam", "DeletePolicyVersion", AccessPermissions, []string{"policy"}, nil},
+	{"iam", "DeleteRole", AccessWrite, []string{"role"}, nil},
+	{"iam", "DeleteRolePermissionsBoundary", AccessPermissions, []string{"role"}, []string{"iam:PermissionsBoundary"}},
+	{"iam", "DeleteRolePolicy", AccessPermissions, []string{"role"}, []string{"iam:PermissionsBoundary"}},
+	{"iam", "DeleteServiceSpecificCredential", AccessWrite, []string{"user"}, nil},
+	{"iam", "DeleteSigningCertificate", AccessWrite, []string{"user"}, nil},
+	{"iam", "DeleteSSHPublicKey", AccessWrite, []string{"user"}, nil},
+	{"iam", "DeleteUser", AccessWrite, []string{"user"}, nil},
+	{"iam", "DeleteUserPermissionsBoundary", AccessPermissions, []string{"user"}, []string{"iam:PermissionsBoundary"}},
+	{"iam", "DeleteUserPolicy", AccessPermissions, []string{"user"}, []string{"iam:PermissionsBoundary"}},
+	{"iam", "DeleteVirtualMFADevice", AccessWrite, []string{"mfa"}, nil},
+	{"iam", "DetachGroupPolicy", AccessPermissions, []string{"group"}, []string{"iam:PolicyARN"}},
+	{"iam", "DetachRolePolicy", AccessPermissions, []string{"role"}, []string{"iam:PermissionsBoundary", "iam:PolicyARN"}},
+	{"iam", "DetachUserPolicy", AccessPermissions, []string{"user"}, []string{"iam:PermissionsBoundary", "iam:PolicyARN"}},
+	{"iam", "EnableMFADevice", AccessWrite, []string{"user"}, nil},
+	{"iam", "GenerateCredentialReport", AccessRead, nil, nil},
+	{"iam", "GetAccessKeyLastUsed", AccessRead, []string{"user"}, nil},
+	{"iam", "GetAccountAuthorizationDetails", AccessRead, nil, nil},
+	{"iam", "GetAccountSummary", AccessList, nil, nil},
+	{"iam", "GetCredentialReport", AccessRead, nil, nil},
+	{"iam", "GetGroup", AccessRead, []string{"group"}, nil},
+	{"iam", "GetGroupPolicy", AccessRead, []string{"group"}, nil},
+	{"iam", "GetInstanceProfile", AccessRead, []string{"instance-profile"}, nil},
+	{"iam", "GetLoginProfile", AccessRead, []string{"user"}, nil},
+	{"iam", "GetPolicy", AccessRead, []string{"policy"}, nil},
+	{"iam", "GetPolicyVersion", AccessRead, []string{"policy"}, nil},
+	{"iam", "GetRole", AccessRead, []string{"role"}, nil},
+	{"iam", "GetRolePolicy", AccessRead, []string{"role"}, nil},
+	{"iam", "GetUser", AccessRead, []string{"user"}, nil},
+	{"iam", "GetUserPolicy", AccessRead, []string{"user"}, nil},
+	{"iam", "ListAccessKeys", AccessList, []string{"user"}, nil},
+	{"iam", "ListAccountAliases", AccessList, nil, nil},
+	{"iam", "ListAttachedGroupPolicies", AccessList, []string{"group"}, nil},
+	{"iam", "ListAttachedRolePolicies", AccessList, []string{"role"}, nil},
+	{"iam", "ListAttachedUserPolicies", AccessList, []string{"user"}, nil},
+	{"iam", "ListEntitiesForPolicy", AccessList, []string{"policy"}, nil},
+	{"iam", "ListGroupPolicies", AccessList, []string{"group"}, nil},
+	{"iam", "ListGroups", AccessList, nil, nil},
+	{"iam", "ListGroupsForUser", AccessList, []string{"user"}, nil},
+	{"iam", "ListInstanceProfiles", AccessList, []string{"instance-profile"}, nil},
+	{"iam", "ListInstanceProfilesForRole", AccessList, []string{"role"}, nil},
+	{"iam", "ListMFADevices", AccessList, []string{"user"}, nil},
+	{"iam", "ListPolicies", AccessList, nil, nil},
+	{"iam", "ListPolicyVersions", AccessList, []string{"policy"}, nil},
+	{"iam", "ListRolePolicies", AccessList, []string{"role"}, nil},
+	{"iam", "ListRoles", AccessList, nil, nil},
+	{"iam", "ListRoleTags", AccessList, []string{"role"}, nil},
+	{"iam", "ListServiceSpecificCredentials", AccessList, []string{"user"}, nil},
+	{"iam", "ListSigningCertificates", AccessList, []string{"user"}, nil},
+	{"iam", "ListSSHPublicKeys", AccessList, []string{"user"}, nil},
+	{"iam", "ListUserPolicies", AccessList, []string{"user"}, nil},
+	{"iam", "ListUsers", AccessList, nil, nil},
+	{"iam", "ListUserTags", AccessList, []string{"user"}, nil},
+	{"iam", "ListVirtualMFADevices", AccessList, nil, nil},
+	{"iam", "PassRole", AccessWrite, []string{"role"}, []string{"iam:AssociatedResourceArn", "iam:PassedToService"}},
+	{"iam", "PutGroupPolicy", AccessPermissions, []string{"group"}, nil},
+	{"iam", "PutRolePermissionsBoundary", AccessPermissions, []string{"role"}, []string{"iam:PermissionsBoundary"}},
+	{"iam", "PutRolePolicy", AccessPermissions, []string{"role"}, []string{"iam:PermissionsBoundary"}},
+	{"iam", "PutUserPermissionsBoundary", AccessPermissions, []string{"user"}, []string{"iam:PermissionsBoundary"}},
+	{"iam", "PutUserPolicy", AccessPermissions, []string{"user"}, []string{"iam:PermissionsBoundary"}},
+	{"iam", "RemoveRoleFromInstanceProfile", AccessWrite, []string{"instance-profile"}, nil},
+	{"iam", "RemoveUserFromGroup", AccessWrite, []string{"group"}, nil},
+	{"iam", "SetDefaultPolicyVersion", AccessPermissions, []string{"policy"}, nil},
+	{"iam", "SimulatePrincipalPolicy", AccessRead, []string{"group", "role", "user"}, nil},
+	{"iam", "TagRole", AccessTagging, []string{"role"}, []string{"aws:RequestTag/${TagKey}", "aws:TagKeys"}},
+	{"iam", "TagUser", AccessTagging, []string{"user"}, []string{"aws:RequestTag/${TagKey}", "aws:TagKeys"}},
+	{"iam", "UntagRole", AccessTagging, []string{"role"}, []string{"aws:TagKeys"}},
+	{"iam", "UntagUser", AccessTagging, []string{"user"}, []string{"aws:TagKeys"}},
+	{"iam", "UpdateAccessKey", AccessWrite, []string{"user"}, nil},
+	{"iam", "UpdateAssumeRolePolicy", AccessPermissions, []string{"role"}, nil},
+	{"iam", "UpdateLoginProfile", AccessWrite, []string{"user"}, nil},
+	{"iam", "UpdateRole", AccessWrite, []string{"role"}, nil},
+	{"iam", "UpdateUser", AccessWrite, []string{"user"}, nil},
+	{"kms", "CancelKeyDeletion", AccessWrite, []string{"key"}, nil},
+	{"kms", "CreateAlias", AccessWrite, []string{"alias", "key"}, nil},
+	{"kms", "CreateGrant", AccessPermissions, []string{"key"}, []string{"kms:CallerAccount", "kms:GrantConstraintType", "kms:GrantIsForAWSResource", "kms:GrantOperations", "kms:GranteePrincipal", "kms:RetiringPrincipal", "kms:ViaService"}},
+	{"kms", "CreateKey", AccessWrite, nil, []string{"kms:BypassPolicyLockoutSafetyCheck", "kms:KeyOrigin"}},
+	{"kms", "Decrypt", AccessWrite, []string{"key"}, []string{"kms:CallerAccount", "kms:EncryptionAlgorithm", "kms:EncryptionContextKeys", "kms:ViaService"}},
+	{"kms", "DeleteAlias", AccessWrite, []string{"alias", "key"}, nil},
+	{"kms", "DescribeKey", AccessRead, []string{"key"}, []string{"kms:CallerAccount", "kms:ViaService"}},
+	{"kms", "DisableKey", AccessWrite, []string{"key"}, nil},
+	{"kms", "DisableKeyRotation", AccessWrite, []string{"key"}, nil},
+	{"kms", "EnableKey", AccessWrite, []string{"key"}, nil},
+	{"kms", "EnableKeyRotation", AccessWrite, []string{"key"}, nil},
+	{"kms", "Encrypt", AccessWrite, []string{"key"}, []string{"kms:CallerAccount", "kms:EncryptionAlgorithm", "kms:EncryptionContextKeys", "kms:ViaService"}},
+	{"kms", "GenerateDataKey", AccessWrite, []string{"key"}, []string{"kms:CallerAccount", "kms:EncryptionAlgorithm", "kms:EncryptionContextKeys", "kms:ViaService"}},
+	{"kms", "GenerateDataKeyWithoutPlaintext", AccessWrite, []string{"key"}, []string{"kms:CallerAccount", "kms:EncryptionAlgorithm", "kms:EncryptionContextKeys", "kms:ViaService"}},
+	{"kms", "GetKeyPolicy", AccessRead, []string{"key"}, nil},
+	{"kms", "GetKeyRotationStatus", AccessRead, []string{"key"}, nil},
+	{"kms", "GetPublicKey", AccessRead, []string{"key"}, nil},
+	{"kms", "ListAliases", AccessList, nil, nil},
+	{"kms", "ListGrants", AccessList, []string{"key"}, nil},
+	{"kms", "ListKeyPolicies", AccessList, []string{"key"}, nil},
+	{"kms", "ListKeys", AccessList, nil, nil},
+	{"kms", "ListResourceTags", AccessRead, []string{"key"}, nil},
+	{"kms", "PutKeyPolicy", AccessPermissions, []string{"key"}, []string{"kms:BypassPolicyLockoutSafetyCheck"}},
+	{"kms", "ReEncryptFrom", AccessWrite, []string{"key"}, []string{"kms:EncryptionContextKeys", "kms:ReEncryptOnSameKey"}},
+	{"kms", "ReEncryptTo", AccessWrite, []string{"key"}, []string{"kms:EncryptionContextKeys", "kms:ReEncryptOnSameKey"}},
+	{"kms", "RetireGrant", AccessPermissions, []string{"key"}, nil},
+	{"kms", "RevokeGrant", AccessPermissions, []string{"key"}, nil},
+	{"kms", "ScheduleKeyDeletion", AccessWrite, []string{"key"}, nil},
+	{"kms", "Sign", AccessWrite, []string{"key"}, []string{"kms:MessageType", "kms:SigningAlgorithm"}},
+	{"kms", "TagResource", AccessTagging, []string{"key"}, []string{"aws:RequestTag/${TagKey}", "aws:TagKeys"}},
+	{"kms", "UntagResource", AccessTagging, []string{"key"}, []string{"aws:TagKeys"}},
+	{"kms", "UpdateAlias", AccessWrite, []string{"alias", "key"}, nil},
+	{"kms", "Verify", AccessWrite, []string{"key"}, []string{"kms:MessageType", "kms:SigningAlgorithm"}},
+	{"lambda", "AddPermission", AccessPermissions, []string{"function"}, []string{"lambda:Principal"}},
+	{"lambda", "CreateAlias", AccessWrite, []string{"function"}, nil},
+	{"lambda", "CreateEventSourceMapping", AccessWrite, nil, []string{"lambda:FunctionArn"}},
+	{"lambda", "CreateFunction", AccessWrite, []string{"function"}, []string{"lambda:Layer", "lambda:VpcIds"}},
+	{"lambda", "DeleteAlias", AccessWrite, []string{"function"}, nil},
+	{"lambda", "DeleteEventSourceMapping", AccessWrite, []string{"eventSourceMapping"}, []string{"lambda:FunctionArn"}},
+	{"lambda", "DeleteFunction", AccessWrite, []string{"function"}, nil},
+	{"lambda", "GetAccountSettings", AccessList, nil, nil},
+	{"lambda", "GetAlias", AccessRead, []string{"function"}, nil},
+	{"lambda", "GetFunction", AccessRead, []string{"function"}, nil},
+	{"lambda", "GetFunctionConfiguration", AccessRead, []string{"function"}, nil},
+	{"lambda", "GetPolicy", AccessRead, []string{"function"}, nil},
+	{"lambda", "InvokeFunction", AccessWrite, []string{"function"}, nil},
+	{"lambda", "ListAliases", AccessList, []string{"function"}, nil},
+	{"lambda", "ListEventSourceMappings", AccessList, nil, nil},
+	{"lambda", "ListFunctions", AccessList, nil, nil},
+	{"lambda", "ListLayers", AccessList, nil, nil},
+	{"lambda", "ListTags", AccessRead, []string{"function"}, nil},
+	{"lambda", "ListVersionsByFunction", AccessList, []string{"function"}, nil},
+	{"lambda", "PublishVersion", AccessWrite, []string{"function"}, nil},
+	{"lambda", "PutFunctionConcurrency", AccessWrite, []string{"function"}, nil},
+	{"lambda", "RemovePermission", AccessPermissions, []string{"function"}, []string{"lambda:Principal"}},
+	{"lambda", "TagResource", AccessTagging, []string{"function"}, []string{"aws:RequestTag/${TagKey}", "aws:TagKeys"}},
+	{"lambda", "UntagResource", AccessTagging, []string{"function"}, []string{"aws:TagKeys"}},
+	{"lambda", "UpdateAlias", AccessWrite, []string{"function"}, nil},
+	{"lambda", "UpdateEventSourceMapping", AccessWrite, []string{"eventSourceMapping"}, []string{"lambda:FunctionArn"}},
+	{"lambda", "UpdateFunctionCode", AccessWrite, []string{"function"}, nil},
+	{"lambda", "UpdateFunctionConfiguration", AccessWrite, []string{"function"}, []string{"lambda:Layer", "lambda:VpcIds"}},
+	{"logs", "CreateLogGroup", AccessWrite, []string{"log-group"}, []string{"aws:RequestTag/${TagKey}", "aws:TagKeys"}},
+	{"logs", "CreateLogStream", AccessWrite, []string{"log-stream"}, nil},
+	{"logs", "DeleteLogGroup", AccessWrite, []string{"log-group"}, nil},
+	{"logs", "DeleteLogStream", AccessWrite, []string{"log-stream"}, nil},
+	{"logs", "DeleteRetentionPolicy", AccessWrite, []string{"log-group"}, nil},
+	{"logs", "DescribeLogGroups", AccessList, nil, nil},
+	{"logs", "DescribeLogStreams", AccessList, []string{"log-group"}, nil},
+	{"logs", "FilterLogEvents", AccessRead, []string{"log-group"}, nil},
+	{"logs", "GetLogEvents", AccessRead, []string{"log-stream"}, nil},
+	{"logs", "ListTagsLogGroup", AccessList, []string{"log-group"}, nil},
+	{"logs", "PutLogEvents", AccessWrite, []string{"log-stream"}, nil},
+	{"logs", "PutRetentionPolicy", AccessWrite, []string{"log-group"}, nil},
+	{"logs", "TagLogGroup", AccessTagging, []string{"log-group"}, []string{"aws:RequestTag/${TagKey}", "aws:TagKeys"}},
+	{"logs", "UntagLogGroup", AccessTagging, []string{"log-group"}, []string{"aws:TagKeys"}},
+	{"s3", "AbortMultipartUpload", AccessWrite, []string{"object"}, nil},
+	{"s3", "CreateBucket", AccessWrite, []string{"bucket"}, []string{"s3:x-amz-acl", "s3:x-amz-grant-full-control", "s3:x-amz-grant-read", "s3:x-amz-grant-write"}},
+	{"s3", "DeleteBucket", AccessWrite, []string{"bucket"}, nil},
+	{"s3", "DeleteBucketPolicy", AccessPermissions, []string{"bucket"}, nil},
+	{"s3", "DeleteObject", AccessWrite, []string{"object"}, nil},
+	{"s3", "DeleteObjectTagging", AccessTagging, []string{"object"}, []string{"s3:ExistingObjectTag/<key>"}},
+	{"s3", "DeleteObjectVersion", AccessWrite, []string{"object"}, []string{"s3:versionid"}},
+	{"s3", "DeleteObjectVersionTagging", AccessTagging, []string{"object"}, []string{"s3:ExistingObjectTag/<key>", "s3:versionid"}},
+	{"s3", "GetAccelerateConfiguration", AccessRead, []string{"bucket"}, nil},
+	{"s3", "GetBucketAcl", AccessRead, []string{"bucket"}, nil},
+	{"s3", "GetBucketCORS", AccessRead, []string{"bucket"}, nil},
+	{"s3", "GetBucketLocation", AccessRead, []string{"bucket"}, nil},
+	{"s3", "GetBucketLogging", AccessRead, []string{"bucket"}, nil},
+	{"s3", "GetBucketNotification", AccessRead, []string{"bucket"}, nil},
+	{"s3", "GetBucketPolicy", AccessRead, []string{"bucket"}, nil},
+	{"s3", "GetBucketPolicyStatus", AccessRead, []string{"bucket"}, nil},
+	{"s3", "GetBucketPublicAccessBlock", AccessRead, []string{"bucket"}, nil},
+	{"s3", "GetBucketTagging", AccessRead, []string{"bucket"}, nil},
+	{"s3", "GetBucketVersioning", AccessRead, []string{"bucket"}, nil},
+	{"s3", "GetBucketWebsite", AccessRead, []string{"bucket"}, nil},
+	{"s3", "GetEncryptionConfiguration", AccessRead, []string{"bucket"}, nil},
+	{"s3", "GetLifecycleConfiguration", AccessRead, []string{"bucket"}, nil},
+	{"s3", "GetObject", AccessRead, []string{"object"}, []string{"s3:ExistingObjectTag/<key>"}},
+	{"s3", "GetObjectAcl", AccessRead, []string{"object"}, []string{"s3:ExistingObjectTag/<key>"}},
+	{"s3", "GetObjectTagging", AccessRead, []string{"object"}, []string{"s3:ExistingObjectTag/<key>"}},
+	{"s3", "GetObjectVersion", AccessRead, []string{"object"}, []string{"s3:ExistingObjectTag/<key>", "s3:versionid"}},
+	{"s3", "GetObjectVersionAcl", AccessRead, []string{"object"}, []string{"s3:ExistingObjectTag/<key>", "s3:versionid"}},
+	{"s3", "GetObjectVersionTagging", AccessRead, []string{"object"}, []string{"s3:ExistingObjectTag/<key>", "s3:versionid"}},
+	{"s3", "GetReplicationConfiguration", AccessRead, []string{"bucket"}, nil},
+	{"s3", "ListAllMyBuckets", AccessList, nil, nil},
+	{"s3", "ListBucket", AccessList, []string{"bucket"}, []string{"s3:delimiter", "s3:max-keys", "s3:prefix"}},
+	{"s3", "ListBucketMultipartUploads", AccessList, []string{"bucket"}, nil},
+	{"s3", "ListBucketVersions", AccessList, []string{"bucket"}, []string{"s3:delimiter", "s3:max-keys", "s3:prefix"}},
+	{"s3", "ListMultipartUploadParts", AccessList, []string{"object"}, nil},
+	{"s3", "PutAccelerateConfiguration", AccessWrite, []string{"bucket"}, nil},
+	{"s3", "PutBucketAcl", AccessPermissions, []string{"bucket"}, []string{"s3:x-amz-acl", "s3:x-amz-grant-full-control", "s3:x-amz-grant-read", "s3:x-amz-grant-write"}},
+	{"s3", "PutBucketCORS", AccessWrite, []string{"bucket"}, nil},
+	{"s3", "PutBucketLogging", AccessWrite, []string{"bucket"}, nil},
+	{"s3", "PutBucketNotification", AccessWrite, []string{"bucket"}, nil},
+	{"s3", "PutBucketPolicy", AccessPermissions, []string{"bucket"}, nil},
+	{"s3", "PutBucketPublicAccessBlock", AccessPermissions, []string{"bucket"}, nil},
+	{"s3", "PutBucketTagging", AccessTagging, []string{"bucket"}, nil},
+	{"s3", "PutBucketVersioning", AccessWrite, []string{"bucket"}, nil},
+	{"s3", "PutBucketWebsite", AccessWrite, []string{"bucket"}, nil},
+	{"s3", "PutEncryptionConfiguration", AccessWrite, []string{"bucket"}, nil},
+	{"s3", "PutLifecycleConfiguration", AccessWrite, []string{"bucket"}, nil},
+	{"s3", "PutObject", AccessWrite, []string{"object"}, []string{"s3:RequestObjectTag/<key>", "s3:RequestObjectTagKeys", "s3:x-amz-acl", "s3:x-amz-server-side-encryption", "s3:x-amz-storage-class"}},
+	{"s3", "PutObjectAcl", AccessPermissions, []string{"object"}, []string{"s3:ExistingObjectTag/<key>", "s3:x-amz-acl"}},
+	{"s3", "PutObjectTagging", AccessTagging, []string{"object"}, []string{"s3:ExistingObjectTag/<key>", "s3:RequestObjectTag/<key>", "s3:RequestObjectTagKeys"}},
+	{"s3", "PutObjectVersionAcl", AccessPermissions, []string{"object"}, []string{"s3:ExistingObjectTag/<key>", "s3:versionid", "s3:x-amz-acl"}},
+	{"s3", "PutObjectVersionTagging", AccessTagging, []string{"object"}, []string{"s3:ExistingObjectTag/<key>", "s3:RequestObjectTag/<key>", "s3:RequestObjectTagKeys", "s3:versionid"}},
+	{"s3", "PutReplicationConfiguration", AccessWrite, []string{"bucket"}, nil},
+	{"s3", "RestoreObject", AccessWrite, []string{"object"}, nil},
+	{"sns", "AddPermission", AccessPermissions, []string{"topic"}, nil},
+	{"sns", "ConfirmSubscription", AccessWrite, []string{"topic"}, nil},
+	{"sns", "CreateTopic", AccessWrite, []string{"topic"}, []string{"aws:RequestTag/${TagKey}", "aws:TagKeys"}},
+	{"sns", "DeleteTopic", AccessWrite, []string{"topic"}, nil},
+	{"sns", "GetSubscriptionAttributes", AccessRead, nil, nil},
+	{"sns", "GetTopicAttributes", AccessRead, []string{"topic"}, nil},
+	{"sns", "ListSubscriptions", AccessList, nil, nil},
+	{"sns", "ListSubscriptionsByTopic", AccessList, []string{"topic"}, nil},
+	{"sns", "ListTagsForResource", AccessList, []string{"topic"}, nil},
+	{"sns", "ListTopics", AccessList, nil, nil},
+	{"sns", "Publish", AccessWrite, []string{"topic"}, nil},
+	{"sns", "RemovePermission", AccessPermissions, []string{"topic"}, nil},
+	{"sns", "SetSubscriptionAttributes", AccessWrite, nil, nil},
+	{"sns", "SetTopicAttributes", AccessWrite, []string{"topic"}, nil},
+	{"sns", "Subscribe", AccessWrite, []string{"topic"}, []string{"sns:Endpoint", "sns:Protocol"}},
+	{"sns", "TagResource", AccessTagging, []string{"topic"}, []string{"aws:RequestTag/${TagKey}", "aws:TagKeys"}},
+	{"sns", "Unsubscribe", AccessWrite, nil, nil},
+	{"sns", "UntagResource", AccessTagging, []string{"topic"}, []string{"aws:TagKeys"}},
+	{"sqs", "AddPermission", AccessPermissions, []string{"queue"}, nil},
+	{"sqs", "ChangeMessageVisibility", AccessWrite, []string{"queue"}, nil},
+	{"sqs", "CreateQueue", AccessWrite, []string{"queue"}, nil},
+	{"sqs", "DeleteMessage", AccessWrite, []string{"queue"}, nil},
+	{"sqs", "DeleteQueue", AccessWrite, []string{"queue"}, nil},
+	{"sqs", "GetQueueAttributes", AccessRead, []string{"queue"}, nil},
+	{"sqs", "GetQueueUrl", AccessRead, []string{"queue"}, nil},
+	{"sqs", "ListDeadLetterSourceQueues", AccessRead, []string{"queue"}, nil},
+	{"sqs", "ListQueues", AccessList, nil, nil},
+	{"sqs", "ListQueueTags", AccessRead, []string{"queue"}, nil},
+	{"sqs", "PurgeQueue", AccessWrite, []string{"queue"}, nil},
+	{"sqs", "ReceiveMessage", AccessRead, []string{"queue"}, nil},
+	{"sqs", "RemovePermission", AccessPermissions, []string{"queue"}, nil},
+	{"sqs", "SendMessage", AccessWrite, []string{"queue"}, nil},
+	{"sqs", "SetQueueAttributes", AccessWrite, []string{"queue"}, nil},
+	{"sqs", "TagQueue", AccessTagging, []string{"queue"}, nil},
+	{"sqs", "UntagQueue", AccessTagging, []string{"queue"}, nil},
+	{"sts", "AssumeRole", AccessWrite, []string{"role"}, []string{"aws:SourceIdentity", "sts:ExternalId", "sts:RoleSessionName", "sts:SourceIdentity", "sts:TransitiveTagKeys"}},
+	{"sts", "AssumeRoleWithSAML", AccessWrite, []string{"role"}, []string{"saml:aud", "saml:iss", "saml:sub", "saml:sub_type", "sts:RoleSessionName"}},
+	{"sts", "AssumeRoleWithWebIdentity", AccessWrite, []string{"role"}, []string{"accounts.google.com:aud", "cognito-identity.amazonaws.com:aud", "sts:RoleSessionName"}},
+	{"sts", "DecodeAuthorizationMessage", AccessWrite, nil, nil},
+	{"sts", "GetAccessKeyInfo", AccessRead, nil, nil},
+	{"sts", "GetCallerIdentity", AccessRead, nil, nil},
+	{"sts", "GetFederationToken", AccessRead, []string{"user"}, []string{"aws:RequestTag/${TagKey}", "aws:TagKeys"}},
+	{"sts", "GetSessionToken", AccessRead, nil, nil},
+	{"sts", "TagSession", AccessTagging, []string{"role", "user"}, []string{"aws:RequestTag/${TagKey}", "aws:TagKeys", "sts:TransitiveTagKeys"}},
+}
+
+var completeServices = map[string]bool{
+	"sqs": true,
+}
@@ -0,0 +1,131 @@
+package iamx
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLookupAction(t *testing.T) {
+	a := LookupAction("S3:getobject")
+	require.NotNil(t, a)
+	assert.Equal(t, "s3:GetObject", a.String())
+	assert.Equal(t, AccessRead, a.Access)
+	assert.Equal(t, "Read", a.Access.String())
+	assert.Equal(t, []string{"object"}, a.Resources)
+	assert.Contains(t, a.Conditions, "s3:ExistingObjectTag/<key>")
+
+	a = LookupAction("iam:PutRolePolicy")
+	require.NotNil(t, a)
+	assert.Equal(t, "Permissions management", a.Access.String())
+
+	assert.Nil(t, LookupAction("s3:Nope"))
+	assert.Nil(t, LookupAction("s3:Get*"))
+}
+
+func TestMatchActions(t *testing.T) {
+	names := func(v []*ActionInfo) (s []string) {
+		for _, a := range v {
+			s = append(s, a.String())
+		}
+		return
+	}
+	assert.Equal(t, []string{"sqs:GetQueueAttributes", "sqs:GetQueueUrl"},
+		names(MatchActions("SQS:get*")))
+	assert.Equal(t, []string{"sts:GetCallerIdentity"},
+		names(MatchActions("sts:GetCallerIdentity")))
+	assert.Nil(t, MatchActions("sts:Nope*"))
+	assert.Nil(t, MatchActions("sts:Nope"))
+}
+
+func TestCatalog(t *testing.T) {
+	seen := make(map[string]bool)
+	for i := range catalog {
+		a := &catalog[i]
+		assert.False(t, seen[a.String()], "%s", a)
+		seen[a.String()] = true
+		assert.NotEqual(t, "Unknown", a.Access.String(), "%s", a)
+		if i > 0 {
+			assert.True(t, catalog[i-1].Service <= a.Service, "%s", a)
+		}
+	}
+	for svc := range completeServices {
+		assert.NotEmpty(t, MatchActions(svc+":*"), "%s", svc)
+	}
+}
+
+func TestExpandActions(t *testing.T) {
+	s := &Statement{
+		Effect: Allow,
+		Action: PolicyMultiVal{"sqs:Get*", "S3:getobject", "foo:Bar",
+			"sqs:Nope*", "sqs:GetQueueUrl"},
+		NotAction: PolicyMultiVal{"*"},
+	}
+	x := s.ExpandActions()
+	assert.Equal(t, PolicyMultiVal{"foo:Bar", "s3:GetObject",
+		"sqs:GetQueueAttributes", "sqs:GetQueueUrl", "sqs:Nope*"}, x.Action)
+	assert.Equal(t, PolicyMultiVal{"*"}, x.NotAction)
+	assert.Nil(t, x.Resource)
+	assert.Equal(t, "sqs:Get*", s.Action[0])
+}
+
+func TestCompressActions(t *testing.T) {
+	require.True(t, completeServices["sqs"])
+	require.False(t, completeServices["s3"])
+	tests := []struct{ in, want PolicyMultiVal }{{
+		in:   nil,
+		want: nil,
+	}, {
+		in:   PolicyMultiVal{"sqs:SendMessage", "*"},
+		want: PolicyMultiVal{"*"},
+	}, {
+		in:   PolicyMultiVal{"sqs:GetQueueAttributes", "sqs:GetQueueUrl"},
+		want: PolicyMultiVal{"sqs:GetQueue*"},
+	}, {
+		in:   PolicyMultiVal{"sqs:sendmessage"},
+		want: PolicyMultiVal{"sqs:SendMessage"},
+	}, {
+		in:   PolicyMultiVal{"sqs:Get*", "sqs:GetQueueUrl"},
+		want: PolicyMultiVal{"sqs:GetQueue*"},
+	}, {
+		in: PolicyMultiVal{"sqs:ListQueues", "sqs:ListQueueTags",
+			"sqs:ListDeadLetterSourceQueues", "sqs:DeleteQueue"},
+		want: PolicyMultiVal{"sqs:DeleteQueue", "sqs:List*"},
+	}, {
+		in:   PolicyMultiVal{"sqs:*Queue*", "sqs:*Message*", "sqs:*Permission"},
+		want: PolicyMultiVal{"sqs:*"},
+	}, {
+		in: PolicyMultiVal{"s3:getobject", "s3:GetObjectAcl",
+			"s3:GetObjectTagging", "foo:Bar"},
+		want: PolicyMultiVal{"foo:Bar", "s3:GetObject", "s3:GetObjectAcl",
+			"s3:GetObjectTagging"},
+	}, {
+		in:   PolicyMultiVal{"s3:Get*", "s3:GetObject"},
+		want: PolicyMultiVal{"s3:Get*"},
+	}, {
+		in:   PolicyMultiVal{"EC2:createtags", "ec2:Describe*", "sqs:Get*"},
+		want: PolicyMultiVal{"ec2:CreateTags", "ec2:Describe*", "sqs:GetQueue*"},
+	}}
+	for _, tc := range tests {
+		s := (&Statement{Action: tc.in}).CompressActions()
+		assert.Equal(t, tc.want, s.Action, "%v", tc.in)
+	}
+
+	// Compression is the inverse of expansion
+	s := &Statement{Action: PolicyMultiVal{"sqs:Delete*", "sqs:GetQueue*",
+		"sqs:List*"}}
+	c := s.ExpandActions().CompressActions()
+	assert.Equal(t, s.Action, c.Action)
+	for _, a := range c.ExpandActions().Action {
+		assert.True(t, s.Action.matchAction(a), "%s", a)
+	}
+
+	// Incomplete services are never compressed
+	var ec2 PolicyMultiVal
+	for _, a := range MatchActions("ec2:*") {
+		ec2 = append(ec2, a.String())
+	}
+	s = (&Statement{Action: ec2}).CompressActions()
+	assert.Equal(t, ec2.normalize(), s.Action)
+}
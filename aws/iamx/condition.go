@@ -0,0 +1,360 @@
+package iamx
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mxk/go-cloud/aws/arn"
+)
+
+// Condition operator set qualifiers.
+const (
+	ForAllValues = "ForAllValues:"
+	ForAnyValue  = "ForAnyValue:"
+)
+
+// ifExists is the condition operator suffix that makes a missing key match.
+const ifExists = "IfExists"
+
+// OperatorError indicates an invalid condition operator.
+type OperatorError string
+
+// Error implements error interface.
+func (e OperatorError) Error() string {
+	return "policy: invalid condition operator " + strconv.Quote(string(e))
+}
+
+// ValueError indicates a policy or context value that is not valid for its
+// condition operator.
+type ValueError struct {
+	Op    string
+	Key   string
+	Value string
+	Err   error
+}
+
+// Error implements error interface.
+func (e *ValueError) Error() string {
+	s := fmt.Sprintf("policy: invalid %s value %q for %q", e.Op, e.Value,
+		e.Key)
+	if e.Err != nil {
+		s += ": " + e.Err.Error()
+	}
+	return s
+}
+
+// Get returns the values of the specified condition key. Keys are compared
+// without regard to case. The second return value is false if the key is not
+// present or has no values.
+func (c Context) Get(key string) ([]string, bool) {
+	v, ok := c[key]
+	if !ok {
+		for k := range c {
+			if strings.EqualFold(k, key) {
+				v, ok = c[k], true
+				break
+			}
+		}
+	}
+	return v, ok && len(v) > 0
+}
+
+// Eval returns true if all conditions in m are satisfied by the request
+// context. All conditions are evaluated to ensure that invalid operators and
+// values are reported even if an earlier condition does not match.
+func (m ConditionMap) Eval(ctx Context) (bool, error) {
+	ops := make([]string, 0, len(m))
+	for op := range m {
+		ops = append(ops, op)
+	}
+	sort.Strings(ops)
+	ok := true
+	for _, op := range ops {
+		match, err := m[op].Eval(op, ctx)
+		if err != nil {
+			return false, err
+		}
+		ok = ok && match
+	}
+	return ok, nil
+}
+
+// Eval returns true if all conditions in c are satisfied by the request
+// context using operator op.
+func (c Conditions) Eval(op string, ctx Context) (bool, error) {
+	o, err := parseCondOp(op)
+	if err != nil {
+		return false, err
+	}
+	keys := make([]string, 0, len(c))
+	for k := range c {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	ok := true
+	for _, k := range keys {
+		match, err := o.eval(k, c[k], ctx)
+		if err != nil {
+			return false, err
+		}
+		ok = ok && match
+	}
+	return ok, nil
+}
+
+// condOp is a parsed condition operator.
+type condOp struct {
+	name     string
+	set      string
+	ifExists bool
+	condType
+}
+
+// condType defines value parsing and comparison for a base operator.
+type condType struct {
+	parse func(string) (interface{}, error)
+	match func(pol, val interface{}) bool
+	neg   bool
+}
+
+// parseCondOp parses condition operator op.
+func parseCondOp(op string) (*condOp, error) {
+	o := &condOp{name: op}
+	base := op
+	for _, q := range [...]string{ForAllValues, ForAnyValue} {
+		if strings.HasPrefix(base, q) {
+			o.set, base = q, base[len(q):]
+			break
+		}
+	}
+	if strings.HasSuffix(base, ifExists) {
+		o.ifExists, base = true, base[:len(base)-len(ifExists)]
+	}
+	t, ok := condOps[base]
+	if !ok || (base == "Null" && (o.set != "" || o.ifExists)) {
+		return nil, OperatorError(op)
+	}
+	o.condType = t
+	return o, nil
+}
+
+// eval evaluates a single condition key.
+func (o *condOp) eval(key string, pol []string, ctx Context) (bool, error) {
+	pv := make([]interface{}, len(pol))
+	for i, s := range pol {
+		v, err := o.parse(s)
+		if err != nil {
+			return false, &ValueError{o.name, key, s, err}
+		}
+		pv[i] = v
+	}
+	vals, exists := ctx.Get(key)
+	if o.match == nil { // Null
+		for _, v := range pv {
+			if v.(bool) == exists {
+				return false, nil
+			}
+		}
+		return true, nil
+	}
+	if !exists {
+		switch {
+		case o.ifExists:
+			return true, nil
+		case o.set == ForAllValues:
+			return true, nil
+		case o.set == ForAnyValue:
+			return false, nil
+		}
+		return o.neg, nil
+	}
+	all := o.set == ForAllValues
+	for _, s := range vals {
+		v, err := o.parse(s)
+		if err != nil {
+			return false, &ValueError{o.name, key, s, err}
+		}
+		match := false
+		for _, p := range pv {
+			if match = o.match(p, v); match {
+				break
+			}
+		}
+		if o.set == "" {
+			// Negated operators without a set qualifier are the negation of
+			// the positive match against any context value.
+			if match {
+				return !o.neg, nil
+			}
+		} else if match != o.neg {
+			if !all {
+				return true, nil
+			}
+		} else if all {
+			return false, nil
+		}
+	}
+	return all || o.set == "" && o.neg, nil
+}
+
+// condOps maps base operator names to their types.
+var condOps = map[string]condType{
+	"StringEquals":              {parseString, stringEquals, false},
+	"StringNotEquals":           {parseString, stringEquals, true},
+	"StringEqualsIgnoreCase":    {parseString, stringEqualFold, false},
+	"StringNotEqualsIgnoreCase": {parseString, stringEqualFold, true},
+	"StringLike":                {parseString, stringLike, false},
+	"StringNotLike":             {parseString, stringLike, true},
+
+	"NumericEquals":            {parseNumber, numericCmp(eq), false},
+	"NumericNotEquals":         {parseNumber, numericCmp(eq), true},
+	"NumericLessThan":          {parseNumber, numericCmp(lt), false},
+	"NumericLessThanEquals":    {parseNumber, numericCmp(le), false},
+	"NumericGreaterThan":       {parseNumber, numericCmp(gt), false},
+	"NumericGreaterThanEquals": {parseNumber, numericCmp(ge), false},
+
+	"DateEquals":            {parseDate, dateCmp(eq), false},
+	"DateNotEquals":         {parseDate, dateCmp(eq), true},
+	"DateLessThan":          {parseDate, dateCmp(lt), false},
+	"DateLessThanEquals":    {parseDate, dateCmp(le), false},
+	"DateGreaterThan":       {parseDate, dateCmp(gt), false},
+	"DateGreaterThanEquals": {parseDate, dateCmp(ge), false},
+
+	"Bool":         {parseBool, boolEquals, false},
+	"BinaryEquals": {parseBinary, binaryEquals, false},
+
+	"IpAddress":    {parseIP, ipMatch, false},
+	"NotIpAddress": {parseIP, ipMatch, true},
+
+	"ArnEquals":    {parseString, arnLike, false},
+	"ArnLike":      {parseString, arnLike, false},
+	"ArnNotEquals": {parseString, arnLike, true},
+	"ArnNotLike":   {parseString, arnLike, true},
+
+	"Null": {parseBool, nil, false},
+}
+
+// Comparison results.
+const (
+	eq = 1 << iota
+	lt
+	gt
+	le = eq | lt
+	ge = eq | gt
+)
+
+func parseString(s string) (interface{}, error) { return s, nil }
+
+func stringEquals(pol, val interface{}) bool {
+	return pol.(string) == val.(string)
+}
+
+func stringEqualFold(pol, val interface{}) bool {
+	return strings.EqualFold(pol.(string), val.(string))
+}
+
+func stringLike(pol, val interface{}) bool {
+	return arn.Glob(pol.(string), val.(string))
+}
+
+func parseNumber(s string) (interface{}, error) {
+	return strconv.ParseFloat(s, 64)
+}
+
+func numericCmp(want int) func(pol, val interface{}) bool {
+	return func(pol, val interface{}) bool {
+		p, v := pol.(float64), val.(float64)
+		switch {
+		case v < p:
+			return want&lt != 0
+		case v > p:
+			return want&gt != 0
+		}
+		return want&eq != 0
+	}
+}
+
+// dateFormats are the accepted ISO 8601 date/time formats.
+var dateFormats = [...]string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05Z0700",
+	"2006-01-02T15:04Z07:00",
+	"2006-01-02T15:04Z0700",
+	"2006-01-02",
+}
+
+func parseDate(s string) (interface{}, error) {
+	if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return time.Unix(n, 0), nil
+	}
+	for _, f := range dateFormats {
+		if t, err := time.Parse(f, s); err == nil {
+			return t, nil
+		}
+	}
+	return nil, errors.New("invalid date/time")
+}
+
+func dateCmp(want int) func(pol, val interface{}) bool {
+	return func(pol, val interface{}) bool {
+		p, v := pol.(time.Time), val.(time.Time)
+		switch {
+		case v.Before(p):
+			return want&lt != 0
+		case v.After(p):
+			return want&gt != 0
+		}
+		return want&eq != 0
+	}
+}
+
+func parseBool(s string) (interface{}, error) {
+	switch strings.ToLower(s) {
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	}
+	return nil, errors.New("invalid boolean")
+}
+
+func boolEquals(pol, val interface{}) bool { return pol.(bool) == val.(bool) }
+
+func parseBinary(s string) (interface{}, error) {
+	return base64.StdEncoding.DecodeString(s)
+}
+
+func binaryEquals(pol, val interface{}) bool {
+	return bytes.Equal(pol.([]byte), val.([]byte))
+}
+
+func parseIP(s string) (interface{}, error) {
+	if strings.IndexByte(s, '/') == -1 {
+		ip := net.ParseIP(s)
+		if ip == nil {
+			return nil, errors.New("invalid IP address")
+		}
+		bits := 8 * net.IPv6len
+		if ip4 := ip.To4(); ip4 != nil {
+			ip, bits = ip4, 8*net.IPv4len
+		}
+		return &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)}, nil
+	}
+	_, n, err := net.ParseCIDR(s)
+	return n, err
+}
+
+func ipMatch(pol, val interface{}) bool {
+	return pol.(*net.IPNet).Contains(val.(*net.IPNet).IP)
+}
+
+func arnLike(pol, val interface{}) bool {
+	return arn.ARN(val.(string)).Match(arn.ARN(pol.(string)))
+}
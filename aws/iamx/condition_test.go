@@ -0,0 +1,168 @@
+package iamx
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConditions(t *testing.T) {
+	ctx := Context{
+		"aws:username":         {"alice"},
+		"aws:SecureTransport":  {"true"},
+		"aws:SourceIp":         {"10.1.2.3"},
+		"aws:CurrentTime":      {"2019-01-15T12:00:00Z"},
+		"aws:EpochTime":        {"1547553600"},
+		"aws:SourceArn":        {"arn:aws:sns:us-east-1:123456789012:topic"},
+		"aws:TagKeys":          {"env", "owner"},
+		"s3:max-keys":          {"10"},
+		"s3:x-amz-grant-read":  {"AAEC"},
+		"ec2:ResourceTag/Name": {"x"},
+	}
+	tests := []*struct {
+		op   string
+		key  string
+		vals PolicyMultiVal
+		want bool
+	}{
+		{"StringEquals", "aws:username", PolicyMultiVal{"bob", "alice"}, true},
+		{"StringEquals", "AWS:UserName", PolicyMultiVal{"alice"}, true},
+		{"StringEquals", "aws:username", PolicyMultiVal{"Alice"}, false},
+		{"StringEquals", "aws:missing", PolicyMultiVal{"alice"}, false},
+		{"StringNotEquals", "aws:username", PolicyMultiVal{"alice"}, false},
+		{"StringNotEquals", "aws:username", PolicyMultiVal{"bob"}, true},
+		{"StringNotEquals", "aws:missing", PolicyMultiVal{"bob"}, true},
+		{"StringEqualsIgnoreCase", "aws:username", PolicyMultiVal{"ALICE"}, true},
+		{"StringNotEqualsIgnoreCase", "aws:username", PolicyMultiVal{"ALICE"}, false},
+		{"StringLike", "aws:username", PolicyMultiVal{"a*e"}, true},
+		{"StringLike", "aws:username", PolicyMultiVal{"a?"}, false},
+		{"StringNotLike", "aws:username", PolicyMultiVal{"b*"}, true},
+		{"StringEqualsIfExists", "aws:missing", PolicyMultiVal{"x"}, true},
+		{"StringEqualsIfExists", "aws:username", PolicyMultiVal{"x"}, false},
+
+		{"NumericEquals", "s3:max-keys", PolicyMultiVal{"10"}, true},
+		{"NumericNotEquals", "s3:max-keys", PolicyMultiVal{"10"}, false},
+		{"NumericLessThan", "s3:max-keys", PolicyMultiVal{"10"}, false},
+		{"NumericLessThanEquals", "s3:max-keys", PolicyMultiVal{"10"}, true},
+		{"NumericGreaterThan", "s3:max-keys", PolicyMultiVal{"9.5"}, true},
+		{"NumericGreaterThanEquals", "s3:max-keys", PolicyMultiVal{"11"}, false},
+
+		{"DateEquals", "aws:CurrentTime", PolicyMultiVal{"1547553600"}, true},
+		{"DateNotEquals", "aws:EpochTime", PolicyMultiVal{"2019-01-15T12:00:00Z"}, false},
+		{"DateLessThan", "aws:CurrentTime", PolicyMultiVal{"2019-01-16"}, true},
+		{"DateLessThanEquals", "aws:CurrentTime", PolicyMultiVal{"2019-01-15"}, false},
+		{"DateGreaterThan", "aws:CurrentTime", PolicyMultiVal{"2019-01-15T11:59Z"}, true},
+		{"DateGreaterThanEquals", "aws:CurrentTime", PolicyMultiVal{"2019-01-15T12:00:00+00:00"}, true},
+
+		{"Bool", "aws:SecureTransport", PolicyMultiVal{"true"}, true},
+		{"Bool", "aws:SecureTransport", PolicyMultiVal{"False"}, false},
+		{"BoolIfExists", "aws:MultiFactorAuthPresent", PolicyMultiVal{"true"}, true},
+		{"BinaryEquals", "s3:x-amz-grant-read", PolicyMultiVal{"AAEC"}, true},
+		{"BinaryEquals", "s3:x-amz-grant-read", PolicyMultiVal{"AAED"}, false},
+
+		{"IpAddress", "aws:SourceIp", PolicyMultiVal{"10.0.0.0/8"}, true},
+		{"IpAddress", "aws:SourceIp", PolicyMultiVal{"10.1.2.3"}, true},
+		{"IpAddress", "aws:SourceIp", PolicyMultiVal{"192.168.0.0/16"}, false},
+		{"NotIpAddress", "aws:SourceIp", PolicyMultiVal{"192.168.0.0/16"}, true},
+		{"NotIpAddress", "aws:SourceIp", PolicyMultiVal{"10.0.0.0/8"}, false},
+
+		{"ArnEquals", "aws:SourceArn", PolicyMultiVal{"arn:aws:sns:us-east-1:123456789012:topic"}, true},
+		{"ArnLike", "aws:SourceArn", PolicyMultiVal{"arn:aws:sns:*:123456789012:*"}, true},
+		{"ArnLike", "aws:SourceArn", PolicyMultiVal{"arn:aws:sns:*"}, false},
+		{"ArnLike", "aws:SourceArn", PolicyMultiVal{"arn:aws:*:*:*:*"}, true},
+		{"ArnNotLike", "aws:SourceArn", PolicyMultiVal{"arn:aws:sqs:*:*:*"}, true},
+		{"ArnNotEquals", "aws:SourceArn", PolicyMultiVal{"arn:aws:sns:*:*:*"}, false},
+
+		{"Null", "aws:missing", PolicyMultiVal{"true"}, true},
+		{"Null", "aws:username", PolicyMultiVal{"true"}, false},
+		{"Null", "aws:username", PolicyMultiVal{"false"}, true},
+
+		{"StringEquals", "aws:TagKeys", PolicyMultiVal{"owner"}, true},
+		{"StringNotEquals", "aws:TagKeys", PolicyMultiVal{"env"}, false},
+		{"StringNotEquals", "aws:TagKeys", PolicyMultiVal{"x"}, true},
+		{"StringNotLike", "aws:TagKeys", PolicyMultiVal{"o*"}, false},
+		{"ForAnyValue:StringEquals", "aws:TagKeys", PolicyMultiVal{"env"}, true},
+		{"ForAnyValue:StringEquals", "aws:TagKeys", PolicyMultiVal{"x"}, false},
+		{"ForAnyValue:StringEquals", "aws:missing", PolicyMultiVal{"x"}, false},
+		{"ForAnyValue:StringNotEquals", "aws:TagKeys", PolicyMultiVal{"env"}, true},
+		{"ForAllValues:StringEquals", "aws:TagKeys", PolicyMultiVal{"env", "owner"}, true},
+		{"ForAllValues:StringEquals", "aws:TagKeys", PolicyMultiVal{"env"}, false},
+		{"ForAllValues:StringEquals", "aws:missing", PolicyMultiVal{"env"}, true},
+		{"ForAllValues:StringNotEquals", "aws:TagKeys", PolicyMultiVal{"x"}, true},
+		{"ForAllValues:StringNotEquals", "aws:TagKeys", PolicyMultiVal{"env"}, false},
+		{"ForAllValues:StringLikeIfExists", "aws:TagKeys", PolicyMultiVal{"*"}, true},
+	}
+	for _, tc := range tests {
+		m := ConditionMap{tc.op: Conditions{tc.key: tc.vals}}
+		have, err := m.Eval(ctx)
+		require.NoError(t, err, "%+v", tc)
+		assert.Equal(t, tc.want, have, "%+v", tc)
+	}
+
+	m := ConditionMap{
+		"StringEquals": {"aws:username": {"alice"}},
+		"Bool":         {"aws:SecureTransport": {"true"}},
+	}
+	ok, err := m.Eval(ctx)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	m["Bool"]["aws:SecureTransport"] = PolicyMultiVal{"false"}
+	ok, err = m.Eval(ctx)
+	require.NoError(t, err)
+	assert.False(t, ok)
+	ok, err = ConditionMap(nil).Eval(nil)
+	require.NoError(t, err)
+	assert.True(t, ok)
+}
+
+func TestConditionErrors(t *testing.T) {
+	ctx := Context{"k": {"x"}}
+	for _, op := range []string{"", "StringEqual", "stringequals",
+		"ForAll:StringEquals", "ForAnyValue:Null", "NullIfExists",
+		"IfExists"} {
+		_, err := ConditionMap{op: {"k": {"x"}}}.Eval(ctx)
+		assert.Equal(t, OperatorError(op), err, "op=%q", op)
+	}
+	tests := []*struct{ op, val string }{
+		{"NumericEquals", "x"},
+		{"DateEquals", "yesterday"},
+		{"Bool", "yes"},
+		{"BinaryEquals", "!"},
+		{"IpAddress", "10.0.0.0/33"},
+		{"IpAddress", "10.0.0"},
+		{"Null", "1"},
+	}
+	for _, tc := range tests {
+		_, err := ConditionMap{tc.op: {"k": {tc.val}}}.Eval(nil)
+		assert.Equal(t, &ValueError{tc.op, "k", tc.val, err.(*ValueError).Err},
+			err, "%+v", tc)
+		assert.Error(t, err.(*ValueError).Err)
+	}
+	_, err := ConditionMap{"NumericEquals": {"k": {"1"}}}.Eval(ctx)
+	require.IsType(t, (*ValueError)(nil), err)
+	assert.Equal(t, "x", err.(*ValueError).Value)
+	assert.Contains(t, err.Error(), `invalid NumericEquals value "x" for "k"`)
+}
+
+func TestEvalCondition(t *testing.T) {
+	p := &Policy{Statement: []*Statement{{
+		Effect:    Allow,
+		Action:    PolicyMultiVal{"s3:GetObject"},
+		Resource:  PolicyMultiVal{"*"},
+		Condition: ConditionMap{"Bool": {"aws:SecureTransport": {"true"}}},
+	}}}
+	q := &Request{Action: "s3:GetObject", Resource: "arn:aws:s3:::b/k"}
+	d, _, err := Eval(q, p)
+	require.NoError(t, err)
+	assert.Equal(t, ImplicitDeny, d)
+
+	q.Context = Context{"aws:SecureTransport": {"true"}}
+	d, _, err = Eval(q, p)
+	require.NoError(t, err)
+	assert.Equal(t, Allowed, d)
+
+	p.Statement[0].Condition = ConditionMap{"Foo": {"k": {"v"}}}
+	_, _, err = Eval(q, p)
+	assert.Equal(t, OperatorError("Foo"), err)
+}
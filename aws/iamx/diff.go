@@ -0,0 +1,365 @@
+package iamx
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// PolicyDiff describes permission changes between two policies. It contains
+// only statements that were added, removed, or modified.
+type PolicyDiff struct {
+	Statements []*StatementDiff
+}
+
+// StatementDiff describes changes to a single statement. Old is nil for added
+// statements and New is nil for removed ones. Old and New indices refer to
+// statement positions in the original policies, or -1 if not present.
+type StatementDiff struct {
+	SID      string
+	Old, New *Statement
+	OldIdx   int
+	NewIdx   int
+
+	Principal    ValDiff
+	NotPrincipal ValDiff
+	Action       ValDiff
+	NotAction    ValDiff
+	Resource     ValDiff
+	NotResource  ValDiff
+	Condition    ValDiff
+}
+
+// ValDiff lists values that were added to and removed from a statement
+// element. Principals are encoded as "<type>:<id>" or "*", and conditions as
+// "<operator> <key>=<value>".
+type ValDiff struct {
+	Added   []string `json:",omitempty"`
+	Removed []string `json:",omitempty"`
+}
+
+// Diff compares policies a and b, ignoring the order of statements and values
+// within each statement element. Statements are matched by SID, then by
+// identical content, and finally by similarity among statements with the same
+// effect. Statements that were not matched by SID are matched by content even
+// if their SIDs differ, so adding, removing, or renaming a SID does not by
+// itself produce a change. Nil policies are treated as empty.
+func Diff(a, b *Policy) *PolicyDiff {
+	var as, bs []*Statement
+	if a != nil {
+		as = a.Statement
+	}
+	if b != nil {
+		bs = b.Statement
+	}
+	xs := make([]*stmtSets, len(as))
+	for i, x := range as {
+		xs[i] = newStmtSets(x)
+	}
+	ys := make([]*stmtSets, len(bs))
+	for j, y := range bs {
+		ys[j] = newStmtSets(y)
+	}
+	pair := make([]int, len(as))
+	used := make([]bool, len(bs))
+	for i := range pair {
+		pair[i] = -1
+	}
+	match := func(fn func(x, y *stmtSets) bool) {
+		for i, x := range xs {
+			if pair[i] >= 0 {
+				continue
+			}
+			for j, y := range ys {
+				if !used[j] && fn(x, y) {
+					pair[i], used[j] = j, true
+					break
+				}
+			}
+		}
+	}
+	match(func(x, y *stmtSets) bool { return x.sid != "" && x.sid == y.sid })
+	match((*stmtSets).equal)
+
+	// Greedy similarity matching for the remaining statements
+	for {
+		bi, bj, best := -1, -1, 0
+		for i, x := range xs {
+			if pair[i] >= 0 {
+				continue
+			}
+			for j, y := range ys {
+				if used[j] || x.effect != y.effect {
+					continue
+				}
+				if n := x.common(y); n > best {
+					bi, bj, best = i, j, n
+				}
+			}
+		}
+		if bi < 0 {
+			break
+		}
+		pair[bi], used[bj] = bj, true
+	}
+
+	d := new(PolicyDiff)
+	for i, x := range as {
+		if j := pair[i]; j >= 0 {
+			if sd := diffStatements(x, bs[j], i, j); sd != nil {
+				d.Statements = append(d.Statements, sd)
+			}
+		} else {
+			d.Statements = append(d.Statements, diffStatements(x, nil, i, -1))
+		}
+	}
+	for j, y := range bs {
+		if !used[j] {
+			d.Statements = append(d.Statements, diffStatements(nil, y, -1, j))
+		}
+	}
+	return d
+}
+
+// Empty returns true if d does not contain any changes.
+func (d *PolicyDiff) Empty() bool { return len(d.Statements) == 0 }
+
+// String returns a human-readable representation of d.
+func (d *PolicyDiff) String() string {
+	var b strings.Builder
+	for _, s := range d.Statements {
+		s.write(&b)
+	}
+	return b.String()
+}
+
+// String returns a human-readable representation of d.
+func (d *StatementDiff) String() string {
+	var b strings.Builder
+	d.write(&b)
+	return b.String()
+}
+
+// Name returns the statement SID or its index if the SID is empty.
+func (d *StatementDiff) Name() string {
+	if d.SID != "" {
+		return fmt.Sprintf("%q", d.SID)
+	}
+	if d.NewIdx >= 0 {
+		return fmt.Sprintf("#%d", d.NewIdx)
+	}
+	return fmt.Sprintf("#%d", d.OldIdx)
+}
+
+// write writes a human-readable representation of d to b.
+func (d *StatementDiff) write(b *strings.Builder) {
+	switch {
+	case d.Old == nil:
+		fmt.Fprintf(b, "+ Statement %s (%s)\n", d.Name(), d.New.Effect)
+	case d.New == nil:
+		fmt.Fprintf(b, "- Statement %s (%s)\n", d.Name(), d.Old.Effect)
+	default:
+		fmt.Fprintf(b, "~ Statement %s\n", d.Name())
+		if d.Old.Effect != d.New.Effect {
+			fmt.Fprintf(b, "  Effect: %s -> %s\n", d.Old.Effect, d.New.Effect)
+		}
+	}
+	elems := [...]struct {
+		name string
+		v    *ValDiff
+	}{
+		{"Principal", &d.Principal},
+		{"NotPrincipal", &d.NotPrincipal},
+		{"Action", &d.Action},
+		{"NotAction", &d.NotAction},
+		{"Resource", &d.Resource},
+		{"NotResource", &d.NotResource},
+		{"Condition", &d.Condition},
+	}
+	for _, e := range elems {
+		for _, v := range e.v.Removed {
+			fmt.Fprintf(b, "  - %s: %s\n", e.name, v)
+		}
+		for _, v := range e.v.Added {
+			fmt.Fprintf(b, "  + %s: %s\n", e.name, v)
+		}
+	}
+}
+
+// empty returns true if v does not contain any changes.
+func (v *ValDiff) empty() bool {
+	return len(v.Added) == 0 && len(v.Removed) == 0
+}
+
+// diffStatements compares statements a and b, either of which may be nil. It
+// returns nil if the statements are equivalent.
+func diffStatements(a, b *Statement, ai, bi int) *StatementDiff {
+	x, y := newStmtSets(a), newStmtSets(b)
+	d := &StatementDiff{
+		SID:          y.sid,
+		Old:          a,
+		New:          b,
+		OldIdx:       ai,
+		NewIdx:       bi,
+		Principal:    diffSets(x.principal, y.principal),
+		NotPrincipal: diffSets(x.notPrincipal, y.notPrincipal),
+		Action:       diffSets(x.action, y.action),
+		NotAction:    diffSets(x.notAction, y.notAction),
+		Resource:     diffSets(x.resource, y.resource),
+		NotResource:  diffSets(x.notResource, y.notResource),
+		Condition:    diffSets(x.condition, y.condition),
+	}
+	if b == nil {
+		d.SID = x.sid
+	}
+	if a != nil && b != nil && a.Effect == b.Effect && d.Principal.empty() &&
+		d.NotPrincipal.empty() && d.Action.empty() && d.NotAction.empty() &&
+		d.Resource.empty() && d.NotResource.empty() && d.Condition.empty() {
+		return nil
+	}
+	return d
+}
+
+// stmtSets contains statement elements as sorted, deduplicated string sets.
+type stmtSets struct {
+	sid          string
+	effect       Effect
+	principal    []string
+	notPrincipal []string
+	action       []string
+	notAction    []string
+	resource     []string
+	notResource  []string
+	condition    []string
+}
+
+// newStmtSets converts statement s to sets. A nil statement produces empty
+// sets.
+func newStmtSets(s *Statement) *stmtSets {
+	if s == nil {
+		return new(stmtSets)
+	}
+	return &stmtSets{
+		sid:          s.SID,
+		effect:       s.Effect,
+		principal:    principalSet(s.Principal),
+		notPrincipal: principalSet(s.NotPrincipal),
+		action:       toSet(s.Action),
+		notAction:    toSet(s.NotAction),
+		resource:     toSet(s.Resource),
+		notResource:  toSet(s.NotResource),
+		condition:    conditionSet(s.Condition),
+	}
+}
+
+// all returns all sets in a fixed order.
+func (s *stmtSets) all() [7][]string {
+	return [...][]string{s.principal, s.notPrincipal, s.action, s.notAction,
+		s.resource, s.notResource, s.condition}
+}
+
+// equal returns true if s and o have the same effect and contents.
+func (s *stmtSets) equal(o *stmtSets) bool {
+	if s.effect != o.effect {
+		return false
+	}
+	a, b := s.all(), o.all()
+	for i := range a {
+		if !PolicyMultiVal(a[i]).Equal(b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// common returns the number of values shared by s and o.
+func (s *stmtSets) common(o *stmtSets) int {
+	n := 0
+	a, b := s.all(), o.all()
+	for i := range a {
+		for _, v := range a[i] {
+			if contains(b[i], v) {
+				n++
+			}
+		}
+	}
+	return n
+}
+
+// principalSet encodes principal p as a set.
+func principalSet(p *Principal) []string {
+	if p == nil {
+		return nil
+	}
+	var v []string
+	if p.Any {
+		v = append(v, "*")
+	}
+	for _, e := range [...]struct {
+		typ PrincipalType
+		ids PolicyMultiVal
+	}{
+		{AWSPrincipal, p.AWS},
+		{FederatedPrincipal, p.Federated},
+		{ServicePrincipal, p.Service},
+	} {
+		for _, id := range e.ids {
+			v = append(v, string(e.typ)+":"+id)
+		}
+	}
+	return toSet(v)
+}
+
+// conditionSet encodes condition map m as a set.
+func conditionSet(m ConditionMap) []string {
+	var v []string
+	for op, conds := range m {
+		for k, vals := range conds {
+			for _, val := range vals {
+				v = append(v, op+" "+k+"="+val)
+			}
+		}
+	}
+	return toSet(v)
+}
+
+// toSet returns a sorted copy of v without duplicates.
+func toSet(v []string) []string {
+	if len(v) == 0 {
+		return nil
+	}
+	s := append(make([]string, 0, len(v)), v...)
+	sort.Strings(s)
+	j := 1
+	for i := 1; i < len(s); i++ {
+		if s[i] != s[j-1] {
+			s[j] = s[i]
+			j++
+		}
+	}
+	return s[:j]
+}
+
+// diffSets returns the differences between sorted sets a and b.
+func diffSets(a, b []string) (d ValDiff) {
+	for i, j := 0, 0; i < len(a) || j < len(b); {
+		switch {
+		case j == len(b) || i < len(a) && a[i] < b[j]:
+			d.Removed = append(d.Removed, a[i])
+			i++
+		case i == len(a) || b[j] < a[i]:
+			d.Added = append(d.Added, b[j])
+			j++
+		default:
+			i++
+			j++
+		}
+	}
+	return
+}
+
+// contains returns true if sorted set v contains s.
+func contains(v []string, s string) bool {
+	i := sort.SearchStrings(v, s)
+	return i < len(v) && v[i] == s
+}
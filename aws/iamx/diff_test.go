@@ -0,0 +1,136 @@
+package iamx
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiff(t *testing.T) {
+	a := `{"Statement": [{
+		"Sid": "Read",
+		"Effect": "Allow",
+		"Action": ["s3:GetObject", "s3:ListBucket"],
+		"Resource": ["arn:aws:s3:::b", "arn:aws:s3:::b/*"]
+	},{
+		"Effect": "Deny",
+		"Action": "s3:DeleteBucket",
+		"Resource": "*"
+	},{
+		"Effect": "Allow",
+		"Principal": {"AWS": "111111111111"},
+		"Action": "sts:AssumeRole",
+		"Condition": {"Bool": {"aws:MultiFactorAuthPresent": "true"}}
+	},{
+		"Sid": "Old",
+		"Effect": "Allow",
+		"Action": "sqs:*",
+		"Resource": "*"
+	}]}`
+	b := `{"Statement": [{
+		"Effect": "Allow",
+		"Principal": {"AWS": ["222222222222", "111111111111"]},
+		"Action": "sts:AssumeRole"
+	},{
+		"Sid": "Read",
+		"Effect": "Allow",
+		"Action": ["s3:ListBucket", "s3:GetObject", "s3:GetObjectTagging"],
+		"Resource": "arn:aws:s3:::b/*"
+	},{
+		"Effect": "Deny",
+		"Action": "s3:DeleteBucket",
+		"Resource": "*"
+	},{
+		"Sid": "New",
+		"Effect": "Deny",
+		"NotAction": "iam:*",
+		"Resource": "*"
+	}]}`
+	pa, err := ParsePolicy(&a)
+	require.NoError(t, err)
+	pb, err := ParsePolicy(&b)
+	require.NoError(t, err)
+
+	d := Diff(pa, pb)
+	require.Len(t, d.Statements, 4)
+	s := d.Statements[0]
+	assert.Equal(t, "Read", s.SID)
+	assert.Equal(t, 0, s.OldIdx)
+	assert.Equal(t, 1, s.NewIdx)
+	assert.Equal(t, ValDiff{Added: []string{"s3:GetObjectTagging"}}, s.Action)
+	assert.Equal(t, ValDiff{Removed: []string{"arn:aws:s3:::b"}}, s.Resource)
+
+	s = d.Statements[1]
+	assert.Equal(t, 2, s.OldIdx)
+	assert.Equal(t, 0, s.NewIdx)
+	assert.Equal(t, ValDiff{Added: []string{"AWS:222222222222"}}, s.Principal)
+	assert.Equal(t, ValDiff{Removed: []string{
+		"Bool aws:MultiFactorAuthPresent=true"}}, s.Condition)
+
+	s = d.Statements[2]
+	assert.Equal(t, "Old", s.SID)
+	assert.Nil(t, s.New)
+	assert.Equal(t, -1, s.NewIdx)
+	assert.Equal(t, ValDiff{Removed: []string{"sqs:*"}}, s.Action)
+
+	s = d.Statements[3]
+	assert.Equal(t, "New", s.SID)
+	assert.Nil(t, s.Old)
+	assert.Equal(t, ValDiff{Added: []string{"iam:*"}}, s.NotAction)
+
+	want := `~ Statement "Read"
+  + Action: s3:GetObjectTagging
+  - Resource: arn:aws:s3:::b
+~ Statement #0
+  + Principal: AWS:222222222222
+  - Condition: Bool aws:MultiFactorAuthPresent=true
+- Statement "Old" (Allow)
+  - Action: sqs:*
+  - Resource: *
++ Statement "New" (Deny)
+  + NotAction: iam:*
+  + Resource: *
+`
+	assert.Equal(t, want, d.String())
+
+	assert.True(t, Diff(pa, pa.Clone()).Empty())
+	assert.True(t, Diff(nil, &Policy{}).Empty())
+	pc := pa.Clone()
+	pc.Statement[1].Effect = Allow
+	d = Diff(pa, pc)
+	require.Len(t, d.Statements, 2)
+	assert.Contains(t, d.Statements[0].String(), "- Statement #1 (Deny)\n")
+	assert.Contains(t, d.Statements[1].String(), "+ Statement #1 (Allow)\n")
+
+	pc = pa.Clone()
+	pc.Statement[1].SID = "Deny"
+	pc.Statement[3].SID = ""
+	assert.True(t, Diff(pa, pc).Empty())
+	pc.Statement[3].Action = PolicyMultiVal{"sqs:*", "sns:*"}
+	d = Diff(pa, pc)
+	require.Len(t, d.Statements, 1)
+	assert.Equal(t, 3, d.Statements[0].OldIdx)
+	assert.Equal(t, 3, d.Statements[0].NewIdx)
+	assert.Equal(t, ValDiff{Added: []string{"sns:*"}}, d.Statements[0].Action)
+
+	pc = pa.Clone()
+	pc.Statement[0].Effect = Deny
+	d = Diff(pa, pc)
+	require.Len(t, d.Statements, 1)
+	assert.Equal(t, "~ Statement \"Read\"\n  Effect: Allow -> Deny\n",
+		d.String())
+}
+
+func TestDiffSets(t *testing.T) {
+	assert.Equal(t, []string(nil), toSet(nil))
+	assert.Equal(t, []string{"a", "b"}, toSet([]string{"b", "a", "b", "a"}))
+	assert.Equal(t, ValDiff{Added: []string{"c"}, Removed: []string{"a"}},
+		diffSets([]string{"a", "b"}, []string{"b", "c"}))
+	assert.Equal(t, ValDiff{}, diffSets(nil, nil))
+	assert.Equal(t, []string{"*", "AWS:a", "Service:b"}, principalSet(&Principal{
+		PrincipalMap: PrincipalMap{AWS: PolicyMultiVal{"a"},
+			Service: PolicyMultiVal{"b"}},
+		Any: true,
+	}))
+}
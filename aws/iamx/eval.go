@@ -0,0 +1,175 @@
+package iamx
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/mxk/go-cloud/aws/arn"
+)
+
+// Decision is the result of policy evaluation.
+type Decision int
+
+// Policy evaluation decisions.
+const (
+	ImplicitDeny Decision = iota
+	ExplicitDeny
+	Allowed
+)
+
+// String implements fmt.Stringer.
+func (d Decision) String() string {
+	switch d {
+	case ExplicitDeny:
+		return "explicitDeny"
+	case Allowed:
+		return "allowed"
+	}
+	return "implicitDeny"
+}
+
+// Request is the context of an API call that is evaluated against one or more
+// policies.
+type Request struct {
+	Action        string
+	Resource      string
+	Principal     string
+	PrincipalType PrincipalType
+	Context       Context
+}
+
+// PrincipalType identifies the key under which a principal is specified in the
+// policy Principal element.
+type PrincipalType string
+
+// Principal types.
+const (
+	AWSPrincipal       = PrincipalType("AWS")
+	FederatedPrincipal = PrincipalType("Federated")
+	ServicePrincipal   = PrincipalType("Service")
+)
+
+// Context contains request condition keys and their values.
+type Context map[string][]string
+
+// Eval evaluates request q against all statements in the specified policies.
+// An explicit deny in any statement overrides all allows. The returned
+// statement is the one that determined the decision, or nil for an implicit
+// deny. Policies that are nil are ignored.
+func Eval(q *Request, policies ...*Policy) (Decision, *Statement, error) {
+	var allow *Statement
+	for _, p := range policies {
+		if p == nil {
+			continue
+		}
+		for _, s := range p.Statement {
+			if s.Effect == Allow && allow != nil {
+				continue
+			}
+			ok, err := s.Match(q)
+			if err != nil {
+				return ImplicitDeny, s, err
+			} else if !ok {
+				continue
+			}
+			switch s.Effect {
+			case Allow:
+				allow = s
+			case Deny:
+				return ExplicitDeny, s, nil
+			default:
+				return ImplicitDeny, s, errors.New(
+					"policy: invalid effect " + string(s.Effect))
+			}
+		}
+	}
+	if allow != nil {
+		return Allowed, allow, nil
+	}
+	return ImplicitDeny, nil, nil
+}
+
+// Match returns true if statement s applies to request q, ignoring its effect.
+// Elements that are not present in the statement are not checked.
+func (s *Statement) Match(q *Request) (bool, error) {
+	if s.Principal != nil && s.NotPrincipal != nil ||
+		s.Action != nil && s.NotAction != nil ||
+		s.Resource != nil && s.NotResource != nil {
+		return false, errors.New("policy: statement element and its " +
+			"negation are both set")
+	}
+	ok := (s.Principal == nil || s.Principal.match(q)) &&
+		(s.NotPrincipal == nil || !s.NotPrincipal.match(q)) &&
+		(s.Action == nil || s.Action.matchAction(q.Action)) &&
+		(s.NotAction == nil || !s.NotAction.matchAction(q.Action)) &&
+		(s.Resource == nil || s.Resource.matchResource(q.Resource)) &&
+		(s.NotResource == nil || !s.NotResource.matchResource(q.Resource))
+	if !ok || len(s.Condition) == 0 {
+		return ok, nil
+	}
+	return s.Condition.Eval(q.Context)
+}
+
+// match returns true if the request principal is specified by p. An AWS
+// account ID or root ARN matches all principals in that account.
+func (p *Principal) match(q *Request) bool {
+	if p.Any {
+		return true
+	}
+	var ids PolicyMultiVal
+	var acct, root string
+	switch q.PrincipalType {
+	case AWSPrincipal, "":
+		ids = p.AWS
+		if r := arn.ARN(q.Principal); r.Valid() && r.Account() != "" {
+			acct = r.Account()
+			root = string(arn.New(r.Partition(), "iam", "", acct, "root"))
+		}
+	case FederatedPrincipal:
+		ids = p.Federated
+	case ServicePrincipal:
+		ids = p.Service
+	}
+	for _, id := range ids {
+		if id == "*" || id == q.Principal ||
+			(acct != "" && (id == acct || id == root)) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchAction returns true if action matches any of the action patterns in v.
+// Action matching is case-insensitive.
+func (v PolicyMultiVal) matchAction(action string) bool {
+	for _, pat := range v {
+		if wildcardMatch(pat, action, true) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchResource returns true if resource matches any of the resource patterns
+// in v. ARN patterns are matched field by field.
+func (v PolicyMultiVal) matchResource(resource string) bool {
+	for _, pat := range v {
+		if strings.HasPrefix(pat, "arn:") {
+			if arn.ARN(resource).Match(arn.ARN(pat)) {
+				return true
+			}
+		} else if arn.Glob(pat, resource) {
+			return true
+		}
+	}
+	return false
+}
+
+// wildcardMatch returns true if s matches pattern pat using arn.Glob rules.
+// Comparison is case-insensitive if fold is true.
+func wildcardMatch(pat, s string, fold bool) bool {
+	if fold {
+		pat, s = strings.ToLower(pat), strings.ToLower(s)
+	}
+	return arn.Glob(pat, s)
+}
@@ -0,0 +1,136 @@
+package iamx
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEval(t *testing.T) {
+	doc := `{
+		"Statement": [{
+			"Sid": "ReadAll",
+			"Effect": "Allow",
+			"Action": ["s3:Get*", "s3:List*"],
+			"Resource": "*"
+		},{
+			"Sid": "NoSecrets",
+			"Effect": "Deny",
+			"Action": "s3:*",
+			"Resource": "arn:aws:s3:::secret/*"
+		},{
+			"Sid": "OnlyIAM",
+			"Effect": "Deny",
+			"NotAction": "iam:*",
+			"Resource": "arn:aws:iam::*:*"
+		},{
+			"Sid": "WriteTmp",
+			"Effect": "Allow",
+			"Action": "s3:PutObject",
+			"NotResource": "arn:aws:s3:::data/*"
+		}]
+	}`
+	p, err := ParsePolicy(&doc)
+	require.NoError(t, err)
+	tests := []*struct {
+		act, res string
+		want     Decision
+		sid      string
+	}{
+		{"s3:GetObject", "arn:aws:s3:::data/x", Allowed, "ReadAll"},
+		{"S3:getobject", "arn:aws:s3:::data/x", Allowed, "ReadAll"},
+		{"s3:ListBucket", "arn:aws:s3:::data", Allowed, "ReadAll"},
+		{"s3:GetObject", "arn:aws:s3:::secret/x", ExplicitDeny, "NoSecrets"},
+		{"s3:GetObject", "arn:aws:iam::1:role/x", ExplicitDeny, "OnlyIAM"},
+		{"iam:GetRole", "arn:aws:iam::1:role/x", ImplicitDeny, ""},
+		{"s3:GetObject", "arn:aws:iam::", Allowed, "ReadAll"},
+		{"s3:PutObject", "arn:aws:s3:::tmp/x", Allowed, "WriteTmp"},
+		{"s3:PutObject", "arn:aws:s3:::data/x", ImplicitDeny, ""},
+		{"s3:DeleteObject", "arn:aws:s3:::tmp/x", ImplicitDeny, ""},
+	}
+	for _, tc := range tests {
+		q := &Request{Action: tc.act, Resource: tc.res}
+		d, s, err := Eval(q, nil, p)
+		require.NoError(t, err)
+		assert.Equal(t, tc.want, d, "%+v", tc)
+		if tc.sid == "" {
+			assert.Nil(t, s, "%+v", tc)
+		} else if assert.NotNil(t, s, "%+v", tc) {
+			assert.Equal(t, tc.sid, s.SID, "%+v", tc)
+		}
+	}
+	d, s, err := Eval(&Request{Action: "s3:GetObject"})
+	assert.Equal(t, ImplicitDeny, d)
+	assert.Nil(t, s)
+	assert.NoError(t, err)
+}
+
+func TestEvalPrincipal(t *testing.T) {
+	p := AssumeRolePolicy(Allow, "111111111111",
+		"arn:aws:iam::222222222222:role/a")
+	p.Statement = append(p.Statement, &Statement{
+		Effect:    Allow,
+		Principal: &Principal{PrincipalMap: PrincipalMap{Service: PolicyMultiVal{"ec2.amazonaws.com"}}},
+		Action:    PolicyMultiVal{"sts:AssumeRole"},
+	}, &Statement{
+		Effect:       Deny,
+		NotPrincipal: NewAWSPrincipal("arn:aws:iam::111111111111:root"),
+		Action:       PolicyMultiVal{"sts:AssumeRole"},
+	})
+	tests := []*struct {
+		typ  PrincipalType
+		id   string
+		want Decision
+	}{
+		{"", "arn:aws:iam::111111111111:user/x", Allowed},
+		{AWSPrincipal, "arn:aws:sts::111111111111:assumed-role/r/s", Allowed},
+		{"", "arn:aws:iam::222222222222:role/a", ExplicitDeny},
+		{"", "arn:aws:iam::222222222222:role/b", ExplicitDeny},
+		{ServicePrincipal, "ec2.amazonaws.com", ExplicitDeny},
+		{FederatedPrincipal, "111111111111", ExplicitDeny},
+	}
+	for _, tc := range tests {
+		q := &Request{Action: "sts:AssumeRole", Principal: tc.id,
+			PrincipalType: tc.typ}
+		d, _, err := Eval(q, p)
+		require.NoError(t, err)
+		assert.Equal(t, tc.want, d, "%+v", tc)
+	}
+
+	p.Statement = p.Statement[:2]
+	q := &Request{Action: "sts:AssumeRole", Principal: "ec2.amazonaws.com",
+		PrincipalType: ServicePrincipal}
+	d, s, err := Eval(q, p)
+	require.NoError(t, err)
+	assert.Equal(t, Allowed, d)
+	assert.Equal(t, p.Statement[1], s)
+
+	p.Statement[0].Principal.Any = true
+	q = &Request{Action: "sts:AssumeRole", Principal: "x"}
+	d, _, err = Eval(q, p)
+	require.NoError(t, err)
+	assert.Equal(t, Allowed, d)
+}
+
+func TestEvalError(t *testing.T) {
+	s := &Statement{Effect: "x", Action: PolicyMultiVal{"*"}}
+	_, _, err := Eval(&Request{Action: "a"}, &Policy{Statement: []*Statement{s}})
+	assert.Error(t, err)
+
+	s.Effect = Allow
+	s.NotAction = s.Action
+	_, err = s.Match(&Request{Action: "a"})
+	assert.Error(t, err)
+}
+
+func TestWildcardMatch(t *testing.T) {
+	assert.True(t, wildcardMatch("S3:Get*", "s3:getobject", true))
+	assert.False(t, wildcardMatch("S3:Get*", "s3:getobject", false))
+}
+
+func TestDecision(t *testing.T) {
+	assert.Equal(t, "implicitDeny", ImplicitDeny.String())
+	assert.Equal(t, "explicitDeny", ExplicitDeny.String())
+	assert.Equal(t, "allowed", Allowed.String())
+}
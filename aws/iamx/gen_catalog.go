@@ -0,0 +1,155 @@
+//go:build ignore
+// +build ignore
+
+// gen_catalog generates catalog_data.go from a JSON snapshot of IAM service
+// actions. The snapshot maps service prefixes to action names and their
+// properties:
+//
+//	{"s3": {"GetObject": {
+//		"Access": "Read",
+//		"Resources": ["object"],
+//		"Conditions": ["s3:ExistingObjectTag/<key>"]
+//	}}}
+//
+// Access must be one of List, Read, Write, Permissions (or "Permissions
+// management"), or Tagging.
+//
+// The -complete flag lists services for which the snapshot contains every
+// action. Only the actions of these services may be compressed into wildcard
+// patterns.
+//
+// Usage:
+//
+//	go run gen_catalog.go [-o catalog_data.go] [-complete svc,...] [catalog.json]
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"go/format"
+	"io/ioutil"
+	"log"
+	"os"
+	"sort"
+	"strings"
+)
+
+type action struct {
+	Access     string
+	Resources  []string
+	Conditions []string
+}
+
+var access = map[string]string{
+	"list":                   "AccessList",
+	"read":                   "AccessRead",
+	"write":                  "AccessWrite",
+	"permissions":            "AccessPermissions",
+	"permissions management": "AccessPermissions",
+	"tagging":                "AccessTagging",
+}
+
+func main() {
+	out := flag.String("o", "catalog_data.go", "output file")
+	complete := flag.String("complete", "",
+		"comma-separated `services` with a complete action snapshot")
+	flag.Parse()
+	in := "catalog.json"
+	if flag.NArg() > 0 {
+		in = flag.Arg(0)
+	}
+	b, err := ioutil.ReadFile(in)
+	if err != nil {
+		log.Fatal(err)
+	}
+	var snap map[string]map[string]*action
+	if err = json.Unmarshal(b, &snap); err != nil {
+		log.Fatalf("%s: %v", in, err)
+	}
+	var svcs []string
+	if *complete != "" {
+		svcs = strings.Split(*complete, ",")
+	}
+	src, err := gen(snap, svcs)
+	if err != nil {
+		log.Fatalf("%s: %v", in, err)
+	}
+	if err = ioutil.WriteFile(*out, src, 0666); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func gen(snap map[string]map[string]*action, complete []string) ([]byte,
+	error) {
+	svcs := make([]string, 0, len(snap))
+	for svc := range snap {
+		if svc == "" || strings.ToLower(svc) != svc ||
+			strings.ContainsAny(svc, ":*? ") {
+			return nil, fmt.Errorf("invalid service prefix %q", svc)
+		}
+		svcs = append(svcs, svc)
+	}
+	sort.Strings(svcs)
+	var b bytes.Buffer
+	b.WriteString("// Code generated by gen_catalog.go; DO NOT EDIT.\n\n")
+	b.WriteString("package iamx\n\n")
+	b.WriteString("var catalog = [...]ActionInfo{\n")
+	for _, svc := range svcs {
+		names := make([]string, 0, len(snap[svc]))
+		for name := range snap[svc] {
+			if name == "" || strings.ContainsAny(name, ":*? ") {
+				return nil, fmt.Errorf("invalid %s action %q", svc, name)
+			}
+			names = append(names, name)
+		}
+		sort.Slice(names, func(i, j int) bool {
+			return strings.ToLower(names[i]) < strings.ToLower(names[j])
+		})
+		for _, name := range names {
+			a := snap[svc][name]
+			acc, ok := access[strings.ToLower(a.Access)]
+			if !ok {
+				return nil, fmt.Errorf("invalid %s:%s access level %q", svc,
+					name, a.Access)
+			}
+			fmt.Fprintf(&b, "\t{%q, %q, %s, %s, %s},\n", svc, name, acc,
+				strList(a.Resources), strList(a.Conditions))
+		}
+	}
+	b.WriteString("}\n\n")
+	b.WriteString("var completeServices = map[string]bool{\n")
+	sort.Strings(complete)
+	for _, svc := range complete {
+		if snap[svc] == nil {
+			return nil, fmt.Errorf("unknown complete service %q", svc)
+		}
+		fmt.Fprintf(&b, "\t%q: true,\n", svc)
+	}
+	b.WriteString("}\n")
+	return format.Source(b.Bytes())
+}
+
+func strList(v []string) string {
+	if len(v) == 0 {
+		return "nil"
+	}
+	v = append([]string(nil), v...)
+	sort.Strings(v)
+	q := make([]string, len(v))
+	for i, s := range v {
+		q[i] = fmt.Sprintf("%q", s)
+	}
+	return "[]string{" + strings.Join(q, ", ") + "}"
+}
+
+func init() {
+	log.SetFlags(0)
+	log.SetPrefix("gen_catalog: ")
+	flag.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: go run gen_catalog.go "+
+			"[-o catalog_data.go] [-complete svc,...] [catalog.json]")
+		flag.PrintDefaults()
+	}
+}
@@ -0,0 +1,122 @@
+package iamx
+
+import (
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+	"github.com/mxk/go-fast"
+)
+
+// DeleteGroups deletes all groups under the specified IAM path.
+func (c Client) DeleteGroups(path string) error {
+	in := iam.ListGroupsInput{PathPrefix: aws.String(path)}
+	r := c.ListGroupsRequest(&in)
+	p := r.Paginate()
+	var groups []string
+	for p.Next() {
+		out := p.CurrentPage().Groups
+		for i := range out {
+			groups = append(groups, aws.StringValue(out[i].GroupName))
+		}
+	}
+	if err := p.Err(); err != nil {
+		return err
+	}
+	return fast.ForEachIO(len(groups), func(i int) error {
+		return c.DeleteGroup(groups[i])
+	})
+}
+
+// DeleteGroup deletes the specified group, ensuring that all prerequisites for
+// deletion are met. Group members are removed from the group, but are not
+// deleted.
+func (c Client) DeleteGroup(group string) error {
+	err := fast.Call(
+		func() error { return c.detachGroupPolicies(group) },
+		func() error { return c.deleteGroupPolicies(group) },
+		func() error { return c.removeGroupUsers(group) },
+	)
+	if err == nil {
+		in := iam.DeleteGroupInput{GroupName: aws.String(group)}
+		_, err = c.DeleteGroupRequest(&in).Send()
+	}
+	return err
+}
+
+// detachGroupPolicies detaches all group policies.
+func (c Client) detachGroupPolicies(group string) error {
+	arns, err := c.attachedGroupPolicies(group)
+	if err != nil {
+		return err
+	}
+	return fast.ForEachIO(len(arns), func(i int) error {
+		in := iam.DetachGroupPolicyInput{
+			GroupName: aws.String(group),
+			PolicyArn: aws.String(arns[i]),
+		}
+		_, err := c.DetachGroupPolicyRequest(&in).Send()
+		return err
+	})
+}
+
+// attachedGroupPolicies returns the ARNs of all managed group policies.
+func (c Client) attachedGroupPolicies(group string) ([]string, error) {
+	in := iam.ListAttachedGroupPoliciesInput{GroupName: aws.String(group)}
+	r := c.ListAttachedGroupPoliciesRequest(&in)
+	p := r.Paginate()
+	var arns []string
+	for p.Next() {
+		out := p.CurrentPage().AttachedPolicies
+		for i := range out {
+			arns = append(arns, aws.StringValue(out[i].PolicyArn))
+		}
+	}
+	return arns, p.Err()
+}
+
+// deleteGroupPolicies deletes all inline group policies.
+func (c Client) deleteGroupPolicies(group string) error {
+	names, err := c.groupPolicyNames(group)
+	if err != nil {
+		return err
+	}
+	return fast.ForEachIO(len(names), func(i int) error {
+		in := iam.DeleteGroupPolicyInput{
+			GroupName:  aws.String(group),
+			PolicyName: aws.String(names[i]),
+		}
+		_, err := c.DeleteGroupPolicyRequest(&in).Send()
+		return err
+	})
+}
+
+// groupPolicyNames returns the names of all inline group policies.
+func (c Client) groupPolicyNames(group string) ([]string, error) {
+	in := iam.ListGroupPoliciesInput{GroupName: aws.String(group)}
+	r := c.ListGroupPoliciesRequest(&in)
+	p := r.Paginate()
+	var names []string
+	for p.Next() {
+		names = append(names, p.CurrentPage().PolicyNames...)
+	}
+	return names, p.Err()
+}
+
+// removeGroupUsers removes all users from the group.
+func (c Client) removeGroupUsers(group string) error {
+	in := iam.GetGroupInput{GroupName: aws.String(group)}
+	r := c.GetGroupRequest(&in)
+	p := r.Paginate()
+	var users []string
+	for p.Next() {
+		out := p.CurrentPage().Users
+		for i := range out {
+			users = append(users, aws.StringValue(out[i].UserName))
+		}
+	}
+	if err := p.Err(); err != nil {
+		return err
+	}
+	return fast.ForEachIO(len(users), func(i int) error {
+		return c.removeUserFromGroup(users[i], group)
+	})
+}
@@ -0,0 +1,37 @@
+package iamx
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeleteGroup(t *testing.T) {
+	m := newMockIAM()
+	c := m.client()
+	m.addUser("/", "u1")
+	m.addUser("/", "u2")
+	g := m.addGroup("/app/", "a", "u1", "u2")
+	g.inline["p"] = `{"Version":"2012-10-17","Statement":[]}`
+	g.attached["arn:aws:iam::aws:policy/ReadOnlyAccess"] = true
+	m.addGroup("/app/", "b")
+	m.addGroup("/other/", "c", "u1")
+
+	require.NoError(t, c.DeleteGroup("a"))
+	assert.Equal(t, []string{
+		"DeleteGroup",
+		"DeleteGroupPolicy",
+		"DetachGroupPolicy",
+		"RemoveUserFromGroup",
+		"RemoveUserFromGroup",
+	}, m.mutations())
+	assert.Nil(t, m.groups["a"])
+	assert.Len(t, m.users, 2)
+
+	require.NoError(t, c.DeleteGroups("/app/"))
+	assert.Equal(t, []string{"DeleteGroup"}, m.mutations())
+	assert.Nil(t, m.groups["b"])
+	assert.NotNil(t, m.groups["c"])
+	assert.Error(t, c.DeleteGroup("b"))
+}
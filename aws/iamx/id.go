@@ -1,5 +1,11 @@
 package iamx
 
+import (
+	"encoding/base32"
+	"errors"
+	"fmt"
+)
+
 // Entity identifies IAM entity type by its ID prefix.
 type Entity string
 
@@ -7,10 +13,13 @@ type Entity string
 // (https://docs.aws.amazon.com/IAM/latest/UserGuide/reference_identifiers.html#identifiers-prefixes).
 const (
 	Invalid         = Entity("")
+	BearerToken     = Entity("ABIA")
+	ContextCred     = Entity("ACCA")
 	Group           = Entity("AGPA")
 	InstanceProfile = Entity("AIPA")
 	ManagedPolicy   = Entity("ANPA")
 	PolicyVersion   = Entity("ANVA")
+	PublicKey       = Entity("APKA")
 	Role            = Entity("AROA")
 	Root            = Entity("A3T")
 	ServerCert      = Entity("ASCA")
@@ -23,6 +32,10 @@ const (
 func Type(id string) (e Entity) {
 	if len(id) >= 4 {
 		switch Entity(id[:3]) {
+		case BearerToken[:3]:
+			e = BearerToken
+		case ContextCred[:3]:
+			e = ContextCred
 		case Group[:3]:
 			e = Group
 		case InstanceProfile[:3]:
@@ -31,6 +44,8 @@ func Type(id string) (e Entity) {
 			e = ManagedPolicy
 		case PolicyVersion[:3]:
 			e = PolicyVersion
+		case PublicKey[:3]:
+			e = PublicKey
 		case Role[:3]:
 			e = Role
 		case Root:
@@ -53,7 +68,93 @@ func Type(id string) (e Entity) {
 	return
 }
 
+// Credential returns true if e identifies a credential rather than an IAM
+// resource.
+func (e Entity) Credential() bool {
+	switch e {
+	case BearerToken, ContextCred, PublicKey, TempKey, UserKey:
+		return true
+	}
+	return false
+}
+
+// Temporary returns true if e identifies a temporary credential issued by STS.
+// All other credentials are long-lived.
+func (e Entity) Temporary() bool { return e == BearerToken || e == TempKey }
+
+// IDError describes an invalid IAM ID.
+type IDError struct {
+	ID     string
+	Reason string
+}
+
+// Error implements error interface.
+func (e *IDError) Error() string {
+	return fmt.Sprintf("iamx: invalid id %q (%s)", e.ID, e.Reason)
+}
+
+// ID length limits.
+const (
+	minIDLen = 16
+	maxIDLen = 128
+)
+
+// ValidID validates the prefix, length, and alphabet of an IAM ID and returns
+// its entity type. Unlike Type, which only examines the prefix, it requires the
+// remainder of the ID to consist of base32 characters (A-Z and 2-7). The
+// returned error is always of type *IDError.
+func ValidID(id string) (Entity, error) {
+	e := Type(id)
+	if e == Invalid {
+		return Invalid, &IDError{id, "unknown prefix"}
+	}
+	if len(id) < minIDLen || maxIDLen < len(id) {
+		return Invalid, &IDError{id, fmt.Sprintf("length must be %d-%d",
+			minIDLen, maxIDLen)}
+	}
+	for i := len(e); i < len(id); i++ {
+		if c := id[i]; !('A' <= c && c <= 'Z' || '2' <= c && c <= '7') {
+			return Invalid, &IDError{id, fmt.Sprintf(
+				"invalid character %q at %d", c, i)}
+		}
+	}
+	return e, nil
+}
+
 // Is returns true if id belongs to the specified entity type.
 func Is(id string, typ Entity) bool {
 	return 0 < len(typ) && len(typ) <= len(id) && id[:len(typ)] == string(typ)
 }
+
+// ErrNoAccount indicates an ID that does not encode its account number, such as
+// an access key ID issued before the current ID format was introduced.
+var ErrNoAccount = errors.New("iamx: id does not encode an account")
+
+// AccountID returns the 12-digit ID of the AWS account that owns the specified
+// access key or unique entity ID. The account is decoded from the ID itself,
+// so no API calls are made. ErrNoAccount is returned for legacy IDs, which
+// do not contain account information.
+func AccountID(id string) (string, error) {
+	if e, err := ValidID(id); err != nil {
+		return "", err
+	} else if e == Root || len(id) < 20 {
+		return "", ErrNoAccount
+	}
+	b, err := base32.StdEncoding.DecodeString(id[4:20])
+	if err != nil {
+		return "", &IDError{id, err.Error()}
+	}
+	if b[0]&0x80 == 0 {
+		return "", ErrNoAccount
+	}
+	// Account is stored in bits 46-7 of the first 6 bytes
+	var v uint64
+	for _, c := range b[:6] {
+		v = v<<8 | uint64(c)
+	}
+	acct := v & 0x7fffffffff80 >> 7
+	if acct > 999999999999 {
+		return "", &IDError{id, "invalid account"}
+	}
+	return fmt.Sprintf("%012d", acct), nil
+}
@@ -0,0 +1,253 @@
+package iamx
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/mxk/go-cloud/aws/arn"
+)
+
+// Severity is the importance of a lint finding.
+type Severity int
+
+// Finding severities.
+const (
+	Low Severity = iota + 1
+	Medium
+	High
+)
+
+// String implements fmt.Stringer.
+func (s Severity) String() string {
+	switch s {
+	case Low:
+		return "LOW"
+	case Medium:
+		return "MEDIUM"
+	case High:
+		return "HIGH"
+	}
+	return "NONE"
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (s Severity) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// Lint check names.
+const (
+	CheckMissingVersion    = "MissingVersion"
+	CheckDuplicateSID      = "DuplicateSid"
+	CheckFullAccess        = "FullAccess"
+	CheckAllowNotAction    = "AllowNotAction"
+	CheckConditionOperator = "ConditionOperator"
+	CheckConditionValue    = "ConditionValue"
+	CheckServicePrincipal  = "ServicePrincipalInAWS"
+	CheckResourceService   = "ResourceServiceMismatch"
+	CheckPassRole          = "PassRoleWildcard"
+)
+
+// Finding is a potential problem found in a policy. Path is a JSON path to the
+// offending element (e.g. "$.Statement[0].Action").
+type Finding struct {
+	Severity Severity
+	Check    string
+	Path     string
+	Message  string
+}
+
+// String implements fmt.Stringer.
+func (f *Finding) String() string {
+	return fmt.Sprintf("%s %s: %s (%s)", f.Severity, f.Path, f.Message,
+		f.Check)
+}
+
+// Findings is a list of lint findings.
+type Findings []*Finding
+
+// Max returns the highest severity of all findings, or 0 if there are none.
+func (fs Findings) Max() Severity {
+	var max Severity
+	for _, f := range fs {
+		if f.Severity > max {
+			max = f.Severity
+		}
+	}
+	return max
+}
+
+// Lint checks policy p for common mistakes and dangerous permissions. Findings
+// are grouped by statement in document order. Condition findings are ordered
+// by operator and then by key, since JSON object order is not preserved.
+func Lint(p *Policy) Findings {
+	var l linter
+	if p.Version == "" {
+		l.add(Medium, CheckMissingVersion, "$.Version",
+			"missing policy version; variables will not be expanded")
+	}
+	sids := make(map[string]int, len(p.Statement))
+	for i, s := range p.Statement {
+		path := "$.Statement[" + strconv.Itoa(i) + "]"
+		if s.SID != "" {
+			if j, dup := sids[s.SID]; dup {
+				l.add(High, CheckDuplicateSID, path+".Sid", fmt.Sprintf(
+					"Sid %q is also used by statement %d", s.SID, j))
+			} else {
+				sids[s.SID] = i
+			}
+		}
+		l.statement(path, s)
+	}
+	return l.fs
+}
+
+// linter accumulates findings.
+type linter struct{ fs Findings }
+
+// add appends a new finding.
+func (l *linter) add(sev Severity, check, path, msg string) {
+	l.fs = append(l.fs, &Finding{sev, check, path, msg})
+}
+
+// statement checks a single statement.
+func (l *linter) statement(path string, s *Statement) {
+	allow := s.Effect == Allow
+	if allow && s.Action.has("*") && s.Resource.has("*") {
+		l.add(High, CheckFullAccess, path,
+			`allows all actions ("*") on all resources ("*")`)
+	}
+	if allow && s.NotAction != nil {
+		l.add(Medium, CheckAllowNotAction, path+".NotAction",
+			"NotAction with Allow grants all other current and future "+
+				"actions")
+	}
+	if allow && s.Resource.has("*") {
+		for i, a := range s.Action {
+			if a != "*" && wildcardMatch(a, "iam:PassRole", true) {
+				l.add(High, CheckPassRole, path+".Action"+idx(s.Action, i),
+					"allows iam:PassRole on all resources")
+			}
+		}
+	}
+	for _, p := range [...]struct {
+		name string
+		p    *Principal
+	}{{"Principal", s.Principal}, {"NotPrincipal", s.NotPrincipal}} {
+		if p.p == nil {
+			continue
+		}
+		for i, id := range p.p.AWS {
+			if strings.Contains(id, ".amazonaws.com") {
+				l.add(High, CheckServicePrincipal,
+					path+"."+p.name+".AWS"+idx(p.p.AWS, i), fmt.Sprintf(
+						"service principal %q must be in Service field", id))
+			}
+		}
+	}
+	l.conditions(path+".Condition", s.Condition)
+	if s.Action != nil && !s.Action.has("*") {
+		for i, r := range s.Resource {
+			if !strings.HasPrefix(r, "arn:") || !arn.ARN(r).Valid() {
+				continue
+			}
+			svc := arn.ARN(r).Service()
+			if !strings.ContainsAny(svc, "*?") &&
+				!s.Action.servesResource(svc) {
+				l.add(Medium, CheckResourceService,
+					path+".Resource"+idx(s.Resource, i), fmt.Sprintf(
+						"%s resource cannot match any statement action", svc))
+			}
+		}
+	}
+}
+
+// conditions checks condition operators and values.
+func (l *linter) conditions(path string, m ConditionMap) {
+	ops := make([]string, 0, len(m))
+	for op := range m {
+		ops = append(ops, op)
+	}
+	sort.Strings(ops)
+	for _, op := range ops {
+		o, err := parseCondOp(op)
+		if err != nil {
+			l.add(High, CheckConditionOperator, path+mapKey(op),
+				"unknown condition operator")
+			continue
+		}
+		keys := make([]string, 0, len(m[op]))
+		for k := range m[op] {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			v := m[op][k]
+			for i, val := range v {
+				if strings.Contains(val, "${") {
+					continue
+				}
+				if _, err := o.parse(val); err != nil {
+					l.add(High, CheckConditionValue,
+						path+mapKey(op)+mapKey(k)+idx(v, i), fmt.Sprintf(
+							"invalid %s value %q", op, val))
+				}
+			}
+		}
+	}
+}
+
+// actionResourceSvc maps action service prefixes to additional resource ARN
+// services that they may act upon.
+var actionResourceSvc = map[string][]string{
+	"sts": {"iam"},
+	"ssm": {"ec2"},
+}
+
+// servesResource returns true if any action in v may apply to a resource
+// belonging to service svc.
+func (v PolicyMultiVal) servesResource(svc string) bool {
+	for _, a := range v {
+		i := strings.IndexByte(a, ':')
+		if i == -1 {
+			return true
+		}
+		prefix := strings.ToLower(a[:i])
+		if wildcardMatch(prefix, svc, true) {
+			return true
+		}
+		for _, alt := range actionResourceSvc[prefix] {
+			if alt == svc {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// has returns true if v contains s.
+func (v PolicyMultiVal) has(s string) bool {
+	for _, e := range v {
+		if e == s {
+			return true
+		}
+	}
+	return false
+}
+
+// mapKey returns the JSON path bracket notation for map key k.
+func mapKey(k string) string {
+	k = strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(k)
+	return "['" + k + "']"
+}
+
+// idx returns the JSON path index suffix for v[i]. Single-value elements are
+// encoded as strings, so they do not have an index.
+func idx(v PolicyMultiVal, i int) string {
+	if len(v) == 1 {
+		return ""
+	}
+	return "[" + strconv.Itoa(i) + "]"
+}
@@ -0,0 +1,82 @@
+package iamx
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLint(t *testing.T) {
+	doc := `{"Statement": [{
+		"Sid": "A",
+		"Effect": "Allow",
+		"Action": "*",
+		"Resource": "*"
+	},{
+		"Sid": "A",
+		"Effect": "Allow",
+		"NotAction": "s3:*",
+		"Resource": "*"
+	},{
+		"Effect": "Allow",
+		"Principal": {"AWS": ["123456789012", "ec2.amazonaws.com"]},
+		"Action": ["sts:AssumeRole", "iam:Pass*"],
+		"Resource": "*",
+		"Condition": {
+			"StringEqualz": {"k": "v"},
+			"NumericLessThan": {"s3:max-keys": ["1", "x", "${aws:x}"]}
+		}
+	},{
+		"Effect": "Allow",
+		"Action": ["s3:GetObject", "sts:AssumeRole"],
+		"Resource": [
+			"arn:aws:s3:::b/*",
+			"arn:aws:iam::123456789012:role/r",
+			"arn:aws:ec2:us-east-1:123456789012:instance/*",
+			"arn:aws:*:us-east-1:123456789012:x"
+		]
+	}]}`
+	p, err := ParsePolicy(&doc)
+	require.NoError(t, err)
+	type finding struct {
+		Severity
+		check, path string
+	}
+	want := []finding{
+		{Medium, CheckMissingVersion, "$.Version"},
+		{High, CheckFullAccess, "$.Statement[0]"},
+		{High, CheckDuplicateSID, "$.Statement[1].Sid"},
+		{Medium, CheckAllowNotAction, "$.Statement[1].NotAction"},
+		{High, CheckPassRole, "$.Statement[2].Action[1]"},
+		{High, CheckServicePrincipal, "$.Statement[2].Principal.AWS[1]"},
+		{High, CheckConditionValue,
+			"$.Statement[2].Condition['NumericLessThan']['s3:max-keys'][1]"},
+		{High, CheckConditionOperator,
+			"$.Statement[2].Condition['StringEqualz']"},
+		{Medium, CheckResourceService, "$.Statement[3].Resource[2]"},
+	}
+	fs := Lint(p)
+	have := make([]finding, len(fs))
+	for i, f := range fs {
+		have[i] = finding{f.Severity, f.Check, f.Path}
+	}
+	assert.Equal(t, want, have)
+	assert.Equal(t, High, fs.Max())
+
+	doc = `{"Version": "2012-10-17", "Statement": [{
+		"Effect": "Deny",
+		"NotAction": "iam:*",
+		"Resource": "*"
+	}]}`
+	p, err = ParsePolicy(&doc)
+	require.NoError(t, err)
+	fs = Lint(p)
+	assert.Empty(t, fs)
+	assert.Equal(t, Severity(0), fs.Max())
+	assert.Equal(t, "NONE", fs.Max().String())
+
+	f := &Finding{High, CheckPassRole, "$.Statement[0].Action", "msg"}
+	assert.Equal(t, "HIGH $.Statement[0].Action: msg (PassRoleWildcard)",
+		f.String())
+}
@@ -0,0 +1,231 @@
+package iamx
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/mxk/go-cloud/aws/arn"
+	"github.com/mxk/go-cloud/aws/awsx"
+	"github.com/mxk/go-fast"
+)
+
+// MaxPolicyVersions is the maximum number of versions that IAM keeps for each
+// customer managed policy.
+const MaxPolicyVersions = 5
+
+// EnsurePolicy creates the customer managed policy identified by ARN r or
+// updates an existing one to match p. The ARN should normally be obtained from
+// ManagedPolicyARN. A new default version is created only if the current
+// default version grants different permissions than p. The oldest non-default
+// versions are deleted as needed to stay within MaxPolicyVersions. The ARN
+// must include the ID of the caller's account, which is verified before any
+// changes are made.
+func (c Client) EnsurePolicy(r arn.ARN, p *Policy) error {
+	r = ManagedPolicyARN("", string(r))
+	if acct := r.Account(); acct == "aws" {
+		return fmt.Errorf("iamx: cannot modify AWS managed policy %q", r)
+	} else if !isAccountID(acct) {
+		return fmt.Errorf("iamx: missing account ID in policy ARN %q", r)
+	} else if cur, err := c.callerAccount(); err != nil {
+		return err
+	} else if acct != cur {
+		return fmt.Errorf("iamx: policy %q is not in the current account "+
+			"(%s)", r, cur)
+	}
+	in := iam.GetPolicyInput{PolicyArn: arn.String(r)}
+	out, err := c.GetPolicyRequest(&in).Send()
+	if err != nil {
+		if awsx.ErrCode(err) != iam.ErrCodeNoSuchEntityException {
+			return err
+		}
+		return c.createPolicy(r, p)
+	}
+	doc, err := c.policyVersion(r, aws.StringValue(out.Policy.DefaultVersionId))
+	if err != nil {
+		return err
+	}
+	if cur, err := ParsePolicy(doc); err == nil &&
+		samePolicy(cur, p, r.Partition()) {
+		return nil
+	}
+	if err = c.prunePolicyVersions(r, MaxPolicyVersions-1); err == nil {
+		in := iam.CreatePolicyVersionInput{
+			PolicyArn:      arn.String(r),
+			PolicyDocument: p.Doc(),
+			SetAsDefault:   aws.Bool(true),
+		}
+		_, err = c.CreatePolicyVersionRequest(&in).Send()
+	}
+	return err
+}
+
+// callerAccount returns the ID of the account that the client credentials
+// belong to.
+func (c Client) callerAccount() (string, error) {
+	in := sts.GetCallerIdentityInput{}
+	out, err := sts.New(c.Config).GetCallerIdentityRequest(&in).Send()
+	if err != nil {
+		return "", err
+	}
+	return aws.StringValue(out.Account), nil
+}
+
+// createPolicy creates a new customer managed policy.
+func (c Client) createPolicy(r arn.ARN, p *Policy) error {
+	path := r.Path()
+	if path == "" {
+		path = "/"
+	}
+	in := iam.CreatePolicyInput{
+		Path:           aws.String(path),
+		PolicyDocument: p.Doc(),
+		PolicyName:     aws.String(r.Name()),
+	}
+	_, err := c.CreatePolicyRequest(&in).Send()
+	return err
+}
+
+// policyVersion returns the document of the specified policy version.
+func (c Client) policyVersion(r arn.ARN, id string) (*string, error) {
+	in := iam.GetPolicyVersionInput{
+		PolicyArn: arn.String(r),
+		VersionId: aws.String(id),
+	}
+	out, err := c.GetPolicyVersionRequest(&in).Send()
+	if err != nil {
+		return nil, err
+	}
+	return out.PolicyVersion.Document, nil
+}
+
+// policyVersions returns all versions of the specified policy sorted by
+// creation date, from oldest to newest.
+func (c Client) policyVersions(r arn.ARN) ([]iam.PolicyVersion, error) {
+	in := iam.ListPolicyVersionsInput{PolicyArn: arn.String(r)}
+	req := c.ListPolicyVersionsRequest(&in)
+	p := req.Paginate()
+	var vs []iam.PolicyVersion
+	for p.Next() {
+		vs = append(vs, p.CurrentPage().Versions...)
+	}
+	sort.SliceStable(vs, func(i, j int) bool {
+		return aws.TimeValue(vs[i].CreateDate).Before(
+			aws.TimeValue(vs[j].CreateDate))
+	})
+	return vs, p.Err()
+}
+
+// prunePolicyVersions deletes the oldest non-default policy versions until no
+// more than max versions remain.
+func (c Client) prunePolicyVersions(r arn.ARN, max int) error {
+	vs, err := c.policyVersions(r)
+	if err != nil {
+		return err
+	}
+	var ids []string
+	for i, n := 0, len(vs); i < len(vs) && n > max; i++ {
+		if !aws.BoolValue(vs[i].IsDefaultVersion) {
+			ids = append(ids, aws.StringValue(vs[i].VersionId))
+			n--
+		}
+	}
+	return fast.ForEachIO(len(ids), func(i int) error {
+		return c.deletePolicyVersion(r, ids[i])
+	})
+}
+
+// deletePolicyVersion deletes a non-default policy version.
+func (c Client) deletePolicyVersion(r arn.ARN, id string) error {
+	in := iam.DeletePolicyVersionInput{
+		PolicyArn: arn.String(r),
+		VersionId: aws.String(id),
+	}
+	_, err := c.DeletePolicyVersionRequest(&in).Send()
+	return err
+}
+
+// DeletePolicies deletes all customer managed policies under the specified
+// IAM path.
+func (c Client) DeletePolicies(path string) error {
+	in := iam.ListPoliciesInput{
+		PathPrefix: aws.String(path),
+		Scope:      iam.PolicyScopeTypeLocal,
+	}
+	r := c.ListPoliciesRequest(&in)
+	p := r.Paginate()
+	var arns []arn.ARN
+	for p.Next() {
+		out := p.CurrentPage().Policies
+		for i := range out {
+			arns = append(arns, arn.Value(out[i].Arn))
+		}
+	}
+	if err := p.Err(); err != nil {
+		return err
+	}
+	return fast.ForEachIO(len(arns), func(i int) error {
+		return c.DeletePolicy(arns[i])
+	})
+}
+
+// DeletePolicy deletes the specified customer managed policy, ensuring that
+// all prerequisites for deletion are met. The policy is detached from all
+// users, groups, and roles, and all non-default versions are deleted.
+func (c Client) DeletePolicy(r arn.ARN) error {
+	err := fast.Call(
+		func() error { return c.detachPolicy(r) },
+		func() error { return c.prunePolicyVersions(r, 1) },
+	)
+	if err == nil {
+		in := iam.DeletePolicyInput{PolicyArn: arn.String(r)}
+		_, err = c.DeletePolicyRequest(&in).Send()
+	}
+	return err
+}
+
+// detachPolicy detaches the policy from all users, groups, and roles.
+func (c Client) detachPolicy(r arn.ARN) error {
+	in := iam.ListEntitiesForPolicyInput{PolicyArn: arn.String(r)}
+	req := c.ListEntitiesForPolicyRequest(&in)
+	p := req.Paginate()
+	var fns []func() error
+	policy := string(r)
+	for p.Next() {
+		out := p.CurrentPage()
+		for i := range out.PolicyUsers {
+			user := aws.StringValue(out.PolicyUsers[i].UserName)
+			fns = append(fns, func() error {
+				in := iam.DetachUserPolicyInput{
+					PolicyArn: aws.String(policy),
+					UserName:  aws.String(user),
+				}
+				_, err := c.DetachUserPolicyRequest(&in).Send()
+				return err
+			})
+		}
+		for i := range out.PolicyGroups {
+			group := aws.StringValue(out.PolicyGroups[i].GroupName)
+			fns = append(fns, func() error {
+				in := iam.DetachGroupPolicyInput{
+					GroupName: aws.String(group),
+					PolicyArn: aws.String(policy),
+				}
+				_, err := c.DetachGroupPolicyRequest(&in).Send()
+				return err
+			})
+		}
+		for i := range out.PolicyRoles {
+			role := aws.StringValue(out.PolicyRoles[i].RoleName)
+			fns = append(fns, func() error {
+				return c.detachRolePolicy(role, policy)
+			})
+		}
+	}
+	if err := p.Err(); err != nil {
+		return err
+	}
+	return fast.Call(fns...)
+}
@@ -0,0 +1,101 @@
+package iamx
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/mxk/go-cloud/aws/arn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnsurePolicy(t *testing.T) {
+	m := newMockIAM()
+	c := m.client()
+	r := arn.ARN("arn:aws:iam::123456789012:policy/app/p")
+	p := &Policy{Statement: []*Statement{{
+		Effect:   Allow,
+		Action:   PolicyMultiVal{"s3:GetObject"},
+		Resource: PolicyMultiVal{"*"},
+	}}}
+
+	// Create
+	require.NoError(t, c.EnsurePolicy(r, p))
+	assert.Equal(t, []string{"CreatePolicy"}, m.mutations())
+	mp := m.policies[string(r)]
+	require.NotNil(t, mp)
+	assert.Equal(t, "/app/", aws.StringValue(mp.policy.Path))
+
+	// No changes
+	p2 := p.Clone()
+	p2.Statement[0].Action = PolicyMultiVal{"S3:GetObject"}
+	require.NoError(t, c.EnsurePolicy(r, p2))
+	assert.Empty(t, m.mutations())
+
+	// New versions
+	for i := 0; i < 2*MaxPolicyVersions; i++ {
+		p.Statement[0].Resource = PolicyMultiVal{
+			"arn:aws:s3:::bucket" + string(rune('a'+i)) + "/*",
+		}
+		require.NoError(t, c.EnsurePolicy(r, p))
+		assert.True(t, len(mp.versions) <= MaxPolicyVersions)
+	}
+	assert.Len(t, mp.versions, MaxPolicyVersions)
+	assert.Equal(t, "v11", aws.StringValue(mp.policy.DefaultVersionId))
+	assert.Equal(t, "v7", aws.StringValue(mp.versions[0].VersionId))
+	cur, err := ParsePolicy(mp.versions[len(mp.versions)-1].Document)
+	require.NoError(t, err)
+	assert.Equal(t, p.Doc(), cur.Doc())
+
+	// AWS managed policies cannot be modified
+	err = c.EnsurePolicy(ManagedPolicyARN("", "ReadOnlyAccess"), p)
+	assert.EqualError(t, err, "iamx: cannot modify AWS managed policy "+
+		`"arn:aws:iam::aws:policy/ReadOnlyAccess"`)
+
+	// Account ID is required
+	m.mutations()
+	err = c.EnsurePolicy("arn:aws:iam:::policy/p", p)
+	assert.EqualError(t, err, "iamx: missing account ID in policy ARN "+
+		`"arn:aws:iam:::policy/p"`)
+	assert.Empty(t, m.mutations())
+
+	// Policies in other accounts are rejected before any changes
+	err = c.EnsurePolicy("arn:aws:iam::210987654321:policy/p", p)
+	assert.EqualError(t, err, "iamx: policy "+
+		`"arn:aws:iam::210987654321:policy/p" is not in the current `+
+		"account (123456789012)")
+	assert.Empty(t, m.mutations())
+	assert.Len(t, m.policies, 1)
+}
+
+func TestDeletePolicies(t *testing.T) {
+	m := newMockIAM()
+	c := m.client()
+	p := AssumeRolePolicy(Allow, "123456789012")
+	a := arn.ARN("arn:aws:iam::123456789012:policy/app/a")
+	b := arn.ARN("arn:aws:iam::123456789012:policy/app/b")
+	o := arn.ARN("arn:aws:iam::123456789012:policy/other/o")
+	require.NoError(t, c.EnsurePolicy(a, p))
+	require.NoError(t, c.EnsurePolicy(a, AssumeRolePolicy(Allow, "*")))
+	require.NoError(t, c.EnsurePolicy(b, p))
+	require.NoError(t, c.EnsurePolicy(o, p))
+	m.addUser("/", "u").attached[string(a)] = true
+	m.addGroup("/", "g").attached[string(a)] = true
+	m.addRole("/", "r", p).attached[string(a)] = true
+	m.addRole("/", "r2", p).attached[string(o)] = true
+	m.mutations()
+
+	require.NoError(t, c.DeletePolicies("/app/"))
+	assert.Equal(t, []string{
+		"DeletePolicy",
+		"DeletePolicy",
+		"DeletePolicyVersion",
+		"DetachGroupPolicy",
+		"DetachRolePolicy",
+		"DetachUserPolicy",
+	}, m.mutations())
+	assert.Len(t, m.policies, 1)
+	assert.NotNil(t, m.policies[string(o)])
+	assert.Empty(t, m.users["u"].attached)
+	assert.True(t, m.roles["r2"].attached[string(o)])
+}
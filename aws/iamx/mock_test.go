@@ -0,0 +1,982 @@
+package iamx
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/awserr"
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/mxk/go-cloud/aws/arn"
+	"github.com/mxk/go-cloud/aws/awsmock"
+)
+
+// mockIAM is an in-memory IAM service for testing Client methods.
+type mockIAM struct {
+	mu       sync.Mutex
+	roles    map[string]*mockRole
+	profiles map[string]*iam.InstanceProfile
+	users    map[string]*mockUser
+	groups   map[string]*mockGroup
+	policies map[string]*mockPolicy
+	nextKey  int
+	calls    []string
+}
+
+// mockPolicy is the state of a single customer managed policy.
+type mockPolicy struct {
+	policy   iam.Policy
+	versions []iam.PolicyVersion
+	next     int
+}
+
+// mockUser is the state of a single mock user.
+type mockUser struct {
+	user     iam.User
+	inline   map[string]string
+	attached map[string]bool
+	tags     map[string]string
+	keys     []iam.AccessKeyMetadata
+	keyUsed  map[string]time.Time
+	login    bool
+	mfa      map[string]bool // Serial number -> active
+	ssh      map[string]bool
+	svcCreds map[string]bool
+	certs    map[string]bool
+}
+
+// mockGroup is the state of a single mock group.
+type mockGroup struct {
+	group    iam.Group
+	inline   map[string]string
+	attached map[string]bool
+	users    map[string]bool
+}
+
+// mockRole is the state of a single mock role.
+type mockRole struct {
+	role     iam.Role
+	inline   map[string]string
+	attached map[string]bool
+	tags     map[string]string
+}
+
+func newMockIAM() *mockIAM {
+	return &mockIAM{
+		roles:    make(map[string]*mockRole),
+		profiles: make(map[string]*iam.InstanceProfile),
+		users:    make(map[string]*mockUser),
+		groups:   make(map[string]*mockGroup),
+		policies: make(map[string]*mockPolicy),
+	}
+}
+
+// client returns a Client that sends all requests to m.
+func (m *mockIAM) client() Client {
+	cfg := awsmock.Config(m.handle)
+	return New(&cfg)
+}
+
+// mutations returns and resets all recorded non-read operations in sorted
+// order.
+func (m *mockIAM) mutations() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	v := m.calls
+	m.calls = nil
+	sort.Strings(v)
+	return v
+}
+
+// addRole creates a new mock role.
+func (m *mockIAM) addRole(path, name string, trust *Policy) *mockRole {
+	r := &mockRole{
+		role: iam.Role{
+			Arn: arn.String(arn.New("aws", "iam", "", "123456789012", "role",
+				path, name)),
+			AssumeRolePolicyDocument: aws.String(
+				url.QueryEscape(*trust.Doc())),
+			CreateDate:         aws.Time(time.Now()),
+			MaxSessionDuration: aws.Int64(3600),
+			Path:               aws.String(path),
+			RoleId:             aws.String("AROA" + strings.ToUpper(name)),
+			RoleName:           aws.String(name),
+		},
+		inline:   make(map[string]string),
+		attached: make(map[string]bool),
+		tags:     make(map[string]string),
+	}
+	m.roles[name] = r
+	return r
+}
+
+// addProfile creates a new instance profile containing the specified roles.
+func (m *mockIAM) addProfile(path, name string, roles ...string) {
+	ip := &iam.InstanceProfile{
+		Arn: arn.String(arn.New("aws", "iam", "", "123456789012",
+			"instance-profile", path, name)),
+		CreateDate:          aws.Time(time.Now()),
+		InstanceProfileId:   aws.String("AIPA" + strings.ToUpper(name)),
+		InstanceProfileName: aws.String(name),
+		Path:                aws.String(path),
+	}
+	for _, r := range roles {
+		ip.Roles = append(ip.Roles, m.roles[r].role)
+	}
+	m.profiles[name] = ip
+}
+
+// profilesFor returns the names of all instance profiles containing role.
+func (m *mockIAM) profilesFor(role string) []string {
+	var names []string
+	for name, ip := range m.profiles {
+		for _, r := range ip.Roles {
+			if aws.StringValue(r.RoleName) == role {
+				names = append(names, name)
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// addUser creates a new mock user.
+func (m *mockIAM) addUser(path, name string) *mockUser {
+	u := &mockUser{
+		user: iam.User{
+			Arn: arn.String(arn.New("aws", "iam", "", "123456789012", "user",
+				path, name)),
+			CreateDate: aws.Time(time.Now()),
+			Path:       aws.String(path),
+			UserId:     aws.String("AIDA" + strings.ToUpper(name)),
+			UserName:   aws.String(name),
+		},
+		inline:   make(map[string]string),
+		attached: make(map[string]bool),
+		tags:     make(map[string]string),
+		keyUsed:  make(map[string]time.Time),
+		mfa:      make(map[string]bool),
+		ssh:      make(map[string]bool),
+		svcCreds: make(map[string]bool),
+		certs:    make(map[string]bool),
+	}
+	m.users[name] = u
+	return u
+}
+
+// addGroup creates a new mock group containing the specified users.
+func (m *mockIAM) addGroup(path, name string, users ...string) *mockGroup {
+	g := &mockGroup{
+		group: iam.Group{
+			Arn: arn.String(arn.New("aws", "iam", "", "123456789012", "group",
+				path, name)),
+			CreateDate: aws.Time(time.Now()),
+			GroupId:    aws.String("AGPA" + strings.ToUpper(name)),
+			GroupName:  aws.String(name),
+			Path:       aws.String(path),
+		},
+		inline:   make(map[string]string),
+		attached: make(map[string]bool),
+		users:    make(map[string]bool),
+	}
+	for _, u := range users {
+		g.users[u] = true
+	}
+	m.groups[name] = g
+	return g
+}
+
+func deleteConflict() error {
+	return awserr.New(iam.ErrCodeDeleteConflictException, "conflict", nil)
+}
+
+func noSuchEntity() error {
+	return awserr.New(iam.ErrCodeNoSuchEntityException, "not found", nil)
+}
+
+func (m *mockIAM) handle(q *aws.Request) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	op := q.Operation.Name
+	if !strings.HasPrefix(op, "Get") && !strings.HasPrefix(op, "List") {
+		m.calls = append(m.calls, op)
+	}
+	role := func(name *string) *mockRole {
+		r := m.roles[aws.StringValue(name)]
+		if r == nil {
+			q.Error = noSuchEntity()
+		}
+		return r
+	}
+	switch in := q.Params.(type) {
+	case *iam.GetRoleInput:
+		if r := role(in.RoleName); r != nil {
+			cp := r.role
+			q.Data = &iam.GetRoleOutput{Role: &cp}
+		}
+	case *iam.CreateRoleInput:
+		name := aws.StringValue(in.RoleName)
+		if m.roles[name] != nil {
+			q.Error = awserr.New(iam.ErrCodeEntityAlreadyExistsException,
+				"exists", nil)
+			return
+		}
+		p, err := ParsePolicy(in.AssumeRolePolicyDocument)
+		if err != nil {
+			q.Error = err
+			return
+		}
+		r := m.addRole(aws.StringValue(in.Path), name, p)
+		if in.MaxSessionDuration != nil {
+			r.role.MaxSessionDuration = in.MaxSessionDuration
+		}
+		if in.PermissionsBoundary != nil {
+			r.role.PermissionsBoundary = &iam.AttachedPermissionsBoundary{
+				PermissionsBoundaryArn: in.PermissionsBoundary,
+			}
+		}
+		for _, t := range in.Tags {
+			r.tags[aws.StringValue(t.Key)] = aws.StringValue(t.Value)
+		}
+		cp := r.role
+		q.Data = &iam.CreateRoleOutput{Role: &cp}
+	case *iam.DeleteRoleInput:
+		if r := role(in.RoleName); r != nil {
+			if len(r.inline) > 0 || len(r.attached) > 0 ||
+				len(m.profilesFor(aws.StringValue(in.RoleName))) > 0 {
+				q.Error = deleteConflict()
+				return
+			}
+			delete(m.roles, aws.StringValue(in.RoleName))
+			q.Data = &iam.DeleteRoleOutput{}
+		}
+	case *iam.UpdateAssumeRolePolicyInput:
+		if r := role(in.RoleName); r != nil {
+			r.role.AssumeRolePolicyDocument = in.PolicyDocument
+			q.Data = &iam.UpdateAssumeRolePolicyOutput{}
+		}
+	case *iam.UpdateRoleInput:
+		if r := role(in.RoleName); r != nil {
+			r.role.MaxSessionDuration = in.MaxSessionDuration
+			q.Data = &iam.UpdateRoleOutput{}
+		}
+	case *iam.PutRolePermissionsBoundaryInput:
+		if r := role(in.RoleName); r != nil {
+			r.role.PermissionsBoundary = &iam.AttachedPermissionsBoundary{
+				PermissionsBoundaryArn: in.PermissionsBoundary,
+			}
+			q.Data = &iam.PutRolePermissionsBoundaryOutput{}
+		}
+	case *iam.DeleteRolePermissionsBoundaryInput:
+		if r := role(in.RoleName); r != nil {
+			if r.role.PermissionsBoundary == nil {
+				q.Error = noSuchEntity()
+				return
+			}
+			r.role.PermissionsBoundary = nil
+			q.Data = &iam.DeleteRolePermissionsBoundaryOutput{}
+		}
+	case *iam.ListRoleTagsInput:
+		if r := role(in.RoleName); r != nil {
+			out := &iam.ListRoleTagsOutput{IsTruncated: aws.Bool(false)}
+			out.Tags = makeTags(r.tags)
+			q.Data = out
+		}
+	case *iam.TagRoleInput:
+		if r := role(in.RoleName); r != nil {
+			for _, t := range in.Tags {
+				r.tags[aws.StringValue(t.Key)] = aws.StringValue(t.Value)
+			}
+			q.Data = &iam.TagRoleOutput{}
+		}
+	case *iam.UntagRoleInput:
+		if r := role(in.RoleName); r != nil {
+			for _, k := range in.TagKeys {
+				delete(r.tags, k)
+			}
+			q.Data = &iam.UntagRoleOutput{}
+		}
+	case *iam.ListRolePoliciesInput:
+		if r := role(in.RoleName); r != nil {
+			out := &iam.ListRolePoliciesOutput{IsTruncated: aws.Bool(false)}
+			for name := range r.inline {
+				out.PolicyNames = append(out.PolicyNames, name)
+			}
+			// IAM does not guarantee any particular order
+			sort.Sort(sort.Reverse(sort.StringSlice(out.PolicyNames)))
+			q.Data = out
+		}
+	case *iam.GetRolePolicyInput:
+		if r := role(in.RoleName); r != nil {
+			doc, ok := r.inline[aws.StringValue(in.PolicyName)]
+			if !ok {
+				q.Error = noSuchEntity()
+				return
+			}
+			q.Data = &iam.GetRolePolicyOutput{
+				PolicyDocument: aws.String(url.QueryEscape(doc)),
+				PolicyName:     in.PolicyName,
+				RoleName:       in.RoleName,
+			}
+		}
+	case *iam.PutRolePolicyInput:
+		if r := role(in.RoleName); r != nil {
+			r.inline[aws.StringValue(in.PolicyName)] =
+				aws.StringValue(in.PolicyDocument)
+			q.Data = &iam.PutRolePolicyOutput{}
+		}
+	case *iam.DeleteRolePolicyInput:
+		if r := role(in.RoleName); r != nil {
+			delete(r.inline, aws.StringValue(in.PolicyName))
+			q.Data = &iam.DeleteRolePolicyOutput{}
+		}
+	case *iam.ListAttachedRolePoliciesInput:
+		if r := role(in.RoleName); r != nil {
+			out := &iam.ListAttachedRolePoliciesOutput{
+				IsTruncated: aws.Bool(false),
+			}
+			for _, a := range mapKeys(r.attached) {
+				out.AttachedPolicies = append(out.AttachedPolicies,
+					iam.AttachedPolicy{PolicyArn: aws.String(a)})
+			}
+			q.Data = out
+		}
+	case *iam.AttachRolePolicyInput:
+		if r := role(in.RoleName); r != nil {
+			r.attached[aws.StringValue(in.PolicyArn)] = true
+			q.Data = &iam.AttachRolePolicyOutput{}
+		}
+	case *iam.DetachRolePolicyInput:
+		if r := role(in.RoleName); r != nil {
+			delete(r.attached, aws.StringValue(in.PolicyArn))
+			q.Data = &iam.DetachRolePolicyOutput{}
+		}
+	case *iam.ListRolesInput:
+		out := &iam.ListRolesOutput{IsTruncated: aws.Bool(false)}
+		for _, r := range m.roles {
+			if strings.HasPrefix(aws.StringValue(r.role.Path),
+				aws.StringValue(in.PathPrefix)) {
+				out.Roles = append(out.Roles, r.role)
+			}
+		}
+		sort.Slice(out.Roles, func(i, j int) bool {
+			return *out.Roles[i].RoleName < *out.Roles[j].RoleName
+		})
+		q.Data = out
+	case *iam.ListInstanceProfilesInput:
+		out := &iam.ListInstanceProfilesOutput{IsTruncated: aws.Bool(false)}
+		for _, ip := range m.profiles {
+			if strings.HasPrefix(aws.StringValue(ip.Path),
+				aws.StringValue(in.PathPrefix)) {
+				out.InstanceProfiles = append(out.InstanceProfiles, *ip)
+			}
+		}
+		sort.Slice(out.InstanceProfiles, func(i, j int) bool {
+			return *out.InstanceProfiles[i].InstanceProfileName <
+				*out.InstanceProfiles[j].InstanceProfileName
+		})
+		q.Data = out
+	case *iam.ListInstanceProfilesForRoleInput:
+		if role(in.RoleName) != nil {
+			out := &iam.ListInstanceProfilesForRoleOutput{
+				IsTruncated: aws.Bool(false),
+			}
+			for _, name := range m.profilesFor(aws.StringValue(in.RoleName)) {
+				out.InstanceProfiles = append(out.InstanceProfiles,
+					*m.profiles[name])
+			}
+			q.Data = out
+		}
+	case *iam.RemoveRoleFromInstanceProfileInput:
+		ip := m.profiles[aws.StringValue(in.InstanceProfileName)]
+		if ip == nil {
+			q.Error = noSuchEntity()
+			return
+		}
+		for i, r := range ip.Roles {
+			if aws.StringValue(r.RoleName) == aws.StringValue(in.RoleName) {
+				ip.Roles = append(ip.Roles[:i:i], ip.Roles[i+1:]...)
+				q.Data = &iam.RemoveRoleFromInstanceProfileOutput{}
+				return
+			}
+		}
+		q.Error = noSuchEntity()
+	case *iam.DeleteInstanceProfileInput:
+		name := aws.StringValue(in.InstanceProfileName)
+		if ip := m.profiles[name]; ip == nil {
+			q.Error = noSuchEntity()
+		} else if len(ip.Roles) > 0 {
+			q.Error = deleteConflict()
+		} else {
+			delete(m.profiles, name)
+			q.Data = &iam.DeleteInstanceProfileOutput{}
+		}
+	case *sts.GetCallerIdentityInput:
+		q.Data = &sts.GetCallerIdentityOutput{
+			Account: aws.String("123456789012"),
+			Arn:     aws.String("arn:aws:iam::123456789012:user/admin"),
+			UserId:  aws.String("AIDAADMIN"),
+		}
+	default:
+		if !m.handleUser(q) && !m.handlePolicy(q) {
+			q.Error = awserr.New("MockError", "unsupported operation "+op,
+				nil)
+		}
+	}
+}
+
+// handleUser handles user and group requests.
+func (m *mockIAM) handleUser(q *aws.Request) bool {
+	user := func(name *string) *mockUser {
+		u := m.users[aws.StringValue(name)]
+		if u == nil {
+			q.Error = noSuchEntity()
+		}
+		return u
+	}
+	group := func(name *string) *mockGroup {
+		g := m.groups[aws.StringValue(name)]
+		if g == nil {
+			q.Error = noSuchEntity()
+		}
+		return g
+	}
+	// del removes key from set m, returning an error if it does not exist.
+	del := func(m map[string]bool, key *string) {
+		if !m[aws.StringValue(key)] {
+			q.Error = noSuchEntity()
+		}
+		delete(m, aws.StringValue(key))
+	}
+	switch in := q.Params.(type) {
+	case *iam.ListUsersInput:
+		out := &iam.ListUsersOutput{IsTruncated: aws.Bool(false)}
+		for _, u := range m.users {
+			if strings.HasPrefix(aws.StringValue(u.user.Path),
+				aws.StringValue(in.PathPrefix)) {
+				out.Users = append(out.Users, u.user)
+			}
+		}
+		sort.Slice(out.Users, func(i, j int) bool {
+			return *out.Users[i].UserName < *out.Users[j].UserName
+		})
+		q.Data = out
+	case *iam.GetUserInput:
+		if u := user(in.UserName); u != nil {
+			cp := u.user
+			q.Data = &iam.GetUserOutput{User: &cp}
+		}
+	case *iam.DeleteUserInput:
+		if u := user(in.UserName); u != nil {
+			if len(u.inline) > 0 || len(u.attached) > 0 ||
+				len(u.keys) > 0 || u.login || len(u.mfa) > 0 ||
+				len(u.ssh) > 0 || len(u.svcCreds) > 0 || len(u.certs) > 0 ||
+				len(m.groupsFor(aws.StringValue(in.UserName))) > 0 {
+				q.Error = deleteConflict()
+				return true
+			}
+			delete(m.users, aws.StringValue(in.UserName))
+			q.Data = &iam.DeleteUserOutput{}
+		}
+	case *iam.ListUserPoliciesInput:
+		if u := user(in.UserName); u != nil {
+			out := &iam.ListUserPoliciesOutput{IsTruncated: aws.Bool(false)}
+			for name := range u.inline {
+				out.PolicyNames = append(out.PolicyNames, name)
+			}
+			sort.Strings(out.PolicyNames)
+			q.Data = out
+		}
+	case *iam.GetUserPolicyInput:
+		if u := user(in.UserName); u != nil {
+			doc, ok := u.inline[aws.StringValue(in.PolicyName)]
+			if !ok {
+				q.Error = noSuchEntity()
+				return true
+			}
+			q.Data = &iam.GetUserPolicyOutput{
+				PolicyDocument: aws.String(url.QueryEscape(doc)),
+				PolicyName:     in.PolicyName,
+				UserName:       in.UserName,
+			}
+		}
+	case *iam.DeleteUserPolicyInput:
+		if u := user(in.UserName); u != nil {
+			delete(u.inline, aws.StringValue(in.PolicyName))
+			q.Data = &iam.DeleteUserPolicyOutput{}
+		}
+	case *iam.ListAttachedUserPoliciesInput:
+		if u := user(in.UserName); u != nil {
+			out := &iam.ListAttachedUserPoliciesOutput{
+				IsTruncated: aws.Bool(false),
+			}
+			for _, a := range mapKeys(u.attached) {
+				out.AttachedPolicies = append(out.AttachedPolicies,
+					iam.AttachedPolicy{PolicyArn: aws.String(a)})
+			}
+			q.Data = out
+		}
+	case *iam.DetachUserPolicyInput:
+		if u := user(in.UserName); u != nil {
+			del(u.attached, in.PolicyArn)
+			q.Data = &iam.DetachUserPolicyOutput{}
+		}
+	case *iam.ListUserTagsInput:
+		if u := user(in.UserName); u != nil {
+			q.Data = &iam.ListUserTagsOutput{
+				IsTruncated: aws.Bool(false),
+				Tags:        makeTags(u.tags),
+			}
+		}
+	case *iam.GetAccessKeyLastUsedInput:
+		out := &iam.GetAccessKeyLastUsedOutput{
+			AccessKeyLastUsed: &iam.AccessKeyLastUsed{},
+		}
+		for _, u := range m.users {
+			if t, ok := u.keyUsed[aws.StringValue(in.AccessKeyId)]; ok {
+				out.AccessKeyLastUsed.LastUsedDate = aws.Time(t)
+				out.UserName = u.user.UserName
+			}
+		}
+		q.Data = out
+	case *iam.ListAccessKeysInput:
+		if u := user(in.UserName); u != nil {
+			q.Data = &iam.ListAccessKeysOutput{
+				AccessKeyMetadata: append([]iam.AccessKeyMetadata(nil),
+					u.keys...),
+				IsTruncated: aws.Bool(false),
+			}
+		}
+	case *iam.CreateAccessKeyInput:
+		if u := user(in.UserName); u != nil {
+			if len(u.keys) >= 2 {
+				q.Error = awserr.New(iam.ErrCodeLimitExceededException,
+					"too many keys", nil)
+				return true
+			}
+			m.nextKey++
+			k := &iam.AccessKey{
+				AccessKeyId:     aws.String(fmt.Sprintf("AKIA%016d", m.nextKey)),
+				CreateDate:      aws.Time(time.Now()),
+				SecretAccessKey: aws.String("secret"),
+				Status:          iam.StatusTypeActive,
+				UserName:        in.UserName,
+			}
+			u.keys = append(u.keys, iam.AccessKeyMetadata{
+				AccessKeyId: k.AccessKeyId,
+				CreateDate:  k.CreateDate,
+				Status:      k.Status,
+				UserName:    k.UserName,
+			})
+			q.Data = &iam.CreateAccessKeyOutput{AccessKey: k}
+		}
+	case *iam.UpdateAccessKeyInput:
+		if u := user(in.UserName); u != nil {
+			for i := range u.keys {
+				if aws.StringValue(u.keys[i].AccessKeyId) ==
+					aws.StringValue(in.AccessKeyId) {
+					u.keys[i].Status = in.Status
+					q.Data = &iam.UpdateAccessKeyOutput{}
+					return true
+				}
+			}
+			q.Error = noSuchEntity()
+		}
+	case *iam.DeleteAccessKeyInput:
+		if u := user(in.UserName); u != nil {
+			for i, k := range u.keys {
+				if aws.StringValue(k.AccessKeyId) ==
+					aws.StringValue(in.AccessKeyId) {
+					u.keys = append(u.keys[:i:i], u.keys[i+1:]...)
+					q.Data = &iam.DeleteAccessKeyOutput{}
+					return true
+				}
+			}
+			q.Error = noSuchEntity()
+		}
+	case *iam.GetLoginProfileInput:
+		if u := user(in.UserName); u != nil {
+			if !u.login {
+				q.Error = noSuchEntity()
+				return true
+			}
+			q.Data = &iam.GetLoginProfileOutput{
+				LoginProfile: &iam.LoginProfile{
+					CreateDate: aws.Time(time.Now()),
+					UserName:   in.UserName,
+				},
+			}
+		}
+	case *iam.DeleteLoginProfileInput:
+		if u := user(in.UserName); u != nil {
+			if !u.login {
+				q.Error = noSuchEntity()
+				return true
+			}
+			u.login = false
+			q.Data = &iam.DeleteLoginProfileOutput{}
+		}
+	case *iam.ListMFADevicesInput:
+		if u := user(in.UserName); u != nil {
+			out := &iam.ListMFADevicesOutput{IsTruncated: aws.Bool(false)}
+			for _, sn := range mapKeys(u.mfa) {
+				out.MFADevices = append(out.MFADevices, iam.MFADevice{
+					EnableDate:   aws.Time(time.Now()),
+					SerialNumber: aws.String(sn),
+					UserName:     in.UserName,
+				})
+			}
+			q.Data = out
+		}
+	case *iam.DeactivateMFADeviceInput:
+		if u := user(in.UserName); u != nil {
+			del(u.mfa, in.SerialNumber)
+			q.Data = &iam.DeactivateMFADeviceOutput{}
+		}
+	case *iam.DeleteVirtualMFADeviceInput:
+		for _, u := range m.users {
+			if _, ok := u.mfa[aws.StringValue(in.SerialNumber)]; ok {
+				q.Error = deleteConflict()
+				return true
+			}
+		}
+		q.Data = &iam.DeleteVirtualMFADeviceOutput{}
+	case *iam.ListSSHPublicKeysInput:
+		if u := user(in.UserName); u != nil {
+			out := &iam.ListSSHPublicKeysOutput{IsTruncated: aws.Bool(false)}
+			for _, id := range mapKeys(u.ssh) {
+				out.SSHPublicKeys = append(out.SSHPublicKeys,
+					iam.SSHPublicKeyMetadata{SSHPublicKeyId: aws.String(id)})
+			}
+			q.Data = out
+		}
+	case *iam.DeleteSSHPublicKeyInput:
+		if u := user(in.UserName); u != nil {
+			del(u.ssh, in.SSHPublicKeyId)
+			q.Data = &iam.DeleteSSHPublicKeyOutput{}
+		}
+	case *iam.ListServiceSpecificCredentialsInput:
+		if u := user(in.UserName); u != nil {
+			out := &iam.ListServiceSpecificCredentialsOutput{}
+			for _, id := range mapKeys(u.svcCreds) {
+				out.ServiceSpecificCredentials = append(
+					out.ServiceSpecificCredentials,
+					iam.ServiceSpecificCredentialMetadata{
+						ServiceSpecificCredentialId: aws.String(id),
+					})
+			}
+			q.Data = out
+		}
+	case *iam.DeleteServiceSpecificCredentialInput:
+		if u := user(in.UserName); u != nil {
+			del(u.svcCreds, in.ServiceSpecificCredentialId)
+			q.Data = &iam.DeleteServiceSpecificCredentialOutput{}
+		}
+	case *iam.ListSigningCertificatesInput:
+		if u := user(in.UserName); u != nil {
+			out := &iam.ListSigningCertificatesOutput{
+				IsTruncated: aws.Bool(false),
+			}
+			for _, id := range mapKeys(u.certs) {
+				out.Certificates = append(out.Certificates,
+					iam.SigningCertificate{CertificateId: aws.String(id)})
+			}
+			q.Data = out
+		}
+	case *iam.DeleteSigningCertificateInput:
+		if u := user(in.UserName); u != nil {
+			del(u.certs, in.CertificateId)
+			q.Data = &iam.DeleteSigningCertificateOutput{}
+		}
+	case *iam.DeleteUserPermissionsBoundaryInput:
+		if u := user(in.UserName); u != nil {
+			if u.user.PermissionsBoundary == nil {
+				q.Error = noSuchEntity()
+				return true
+			}
+			u.user.PermissionsBoundary = nil
+			q.Data = &iam.DeleteUserPermissionsBoundaryOutput{}
+		}
+	case *iam.ListGroupsForUserInput:
+		if user(in.UserName) != nil {
+			out := &iam.ListGroupsForUserOutput{IsTruncated: aws.Bool(false)}
+			for _, g := range m.groupsFor(aws.StringValue(in.UserName)) {
+				out.Groups = append(out.Groups, m.groups[g].group)
+			}
+			q.Data = out
+		}
+	case *iam.RemoveUserFromGroupInput:
+		if g := group(in.GroupName); g != nil {
+			del(g.users, in.UserName)
+			q.Data = &iam.RemoveUserFromGroupOutput{}
+		}
+	case *iam.ListGroupsInput:
+		out := &iam.ListGroupsOutput{IsTruncated: aws.Bool(false)}
+		for _, g := range m.groups {
+			if strings.HasPrefix(aws.StringValue(g.group.Path),
+				aws.StringValue(in.PathPrefix)) {
+				out.Groups = append(out.Groups, g.group)
+			}
+		}
+		sort.Slice(out.Groups, func(i, j int) bool {
+			return *out.Groups[i].GroupName < *out.Groups[j].GroupName
+		})
+		q.Data = out
+	case *iam.GetGroupInput:
+		if g := group(in.GroupName); g != nil {
+			out := &iam.GetGroupOutput{
+				Group:       &g.group,
+				IsTruncated: aws.Bool(false),
+			}
+			for _, u := range mapKeys(g.users) {
+				out.Users = append(out.Users, m.users[u].user)
+			}
+			q.Data = out
+		}
+	case *iam.ListGroupPoliciesInput:
+		if g := group(in.GroupName); g != nil {
+			out := &iam.ListGroupPoliciesOutput{IsTruncated: aws.Bool(false)}
+			for name := range g.inline {
+				out.PolicyNames = append(out.PolicyNames, name)
+			}
+			sort.Strings(out.PolicyNames)
+			q.Data = out
+		}
+	case *iam.GetGroupPolicyInput:
+		if g := group(in.GroupName); g != nil {
+			doc, ok := g.inline[aws.StringValue(in.PolicyName)]
+			if !ok {
+				q.Error = noSuchEntity()
+				return true
+			}
+			q.Data = &iam.GetGroupPolicyOutput{
+				GroupName:      in.GroupName,
+				PolicyDocument: aws.String(url.QueryEscape(doc)),
+				PolicyName:     in.PolicyName,
+			}
+		}
+	case *iam.DeleteGroupPolicyInput:
+		if g := group(in.GroupName); g != nil {
+			delete(g.inline, aws.StringValue(in.PolicyName))
+			q.Data = &iam.DeleteGroupPolicyOutput{}
+		}
+	case *iam.ListAttachedGroupPoliciesInput:
+		if g := group(in.GroupName); g != nil {
+			out := &iam.ListAttachedGroupPoliciesOutput{
+				IsTruncated: aws.Bool(false),
+			}
+			for _, a := range mapKeys(g.attached) {
+				out.AttachedPolicies = append(out.AttachedPolicies,
+					iam.AttachedPolicy{PolicyArn: aws.String(a)})
+			}
+			q.Data = out
+		}
+	case *iam.DetachGroupPolicyInput:
+		if g := group(in.GroupName); g != nil {
+			del(g.attached, in.PolicyArn)
+			q.Data = &iam.DetachGroupPolicyOutput{}
+		}
+	case *iam.DeleteGroupInput:
+		if g := group(in.GroupName); g != nil {
+			if len(g.inline) > 0 || len(g.attached) > 0 || len(g.users) > 0 {
+				q.Error = deleteConflict()
+				return true
+			}
+			delete(m.groups, aws.StringValue(in.GroupName))
+			q.Data = &iam.DeleteGroupOutput{}
+		}
+	default:
+		return false
+	}
+	return true
+}
+
+// handlePolicy handles customer managed policy requests.
+func (m *mockIAM) handlePolicy(q *aws.Request) bool {
+	policy := func(r *string) *mockPolicy {
+		p := m.policies[aws.StringValue(r)]
+		if p == nil {
+			q.Error = noSuchEntity()
+		}
+		return p
+	}
+	switch in := q.Params.(type) {
+	case *iam.CreatePolicyInput:
+		r := arn.New("aws", "iam", "", "123456789012", "policy",
+			aws.StringValue(in.Path), aws.StringValue(in.PolicyName))
+		if m.policies[string(r)] != nil {
+			q.Error = awserr.New(iam.ErrCodeEntityAlreadyExistsException,
+				"exists", nil)
+			return true
+		}
+		p := &mockPolicy{policy: iam.Policy{
+			Arn:        arn.String(r),
+			CreateDate: aws.Time(time.Now()),
+			Path:       in.Path,
+			PolicyName: in.PolicyName,
+		}}
+		m.policies[string(r)] = p
+		p.addVersion(in.PolicyDocument)
+		q.Data = &iam.CreatePolicyOutput{Policy: &p.policy}
+	case *iam.GetPolicyInput:
+		if p := policy(in.PolicyArn); p != nil {
+			cp := p.policy
+			q.Data = &iam.GetPolicyOutput{Policy: &cp}
+		}
+	case *iam.GetPolicyVersionInput:
+		if p := policy(in.PolicyArn); p != nil {
+			for i := range p.versions {
+				v := &p.versions[i]
+				if aws.StringValue(v.VersionId) == aws.StringValue(in.VersionId) {
+					cp := *v
+					cp.Document = aws.String(
+						url.QueryEscape(aws.StringValue(v.Document)))
+					q.Data = &iam.GetPolicyVersionOutput{PolicyVersion: &cp}
+					return true
+				}
+			}
+			q.Error = noSuchEntity()
+		}
+	case *iam.ListPolicyVersionsInput:
+		if p := policy(in.PolicyArn); p != nil {
+			out := &iam.ListPolicyVersionsOutput{IsTruncated: aws.Bool(false)}
+			for _, v := range p.versions {
+				v.Document = nil
+				out.Versions = append(out.Versions, v)
+			}
+			q.Data = out
+		}
+	case *iam.CreatePolicyVersionInput:
+		if p := policy(in.PolicyArn); p != nil {
+			if len(p.versions) >= 5 {
+				q.Error = awserr.New(iam.ErrCodeLimitExceededException,
+					"too many versions", nil)
+				return true
+			}
+			v := p.addVersion(in.PolicyDocument)
+			q.Data = &iam.CreatePolicyVersionOutput{PolicyVersion: v}
+		}
+	case *iam.DeletePolicyVersionInput:
+		if p := policy(in.PolicyArn); p != nil {
+			for i, v := range p.versions {
+				if aws.StringValue(v.VersionId) != aws.StringValue(in.VersionId) {
+					continue
+				}
+				if aws.BoolValue(v.IsDefaultVersion) {
+					q.Error = deleteConflict()
+					return true
+				}
+				p.versions = append(p.versions[:i:i], p.versions[i+1:]...)
+				q.Data = &iam.DeletePolicyVersionOutput{}
+				return true
+			}
+			q.Error = noSuchEntity()
+		}
+	case *iam.ListPoliciesInput:
+		out := &iam.ListPoliciesOutput{IsTruncated: aws.Bool(false)}
+		for _, p := range m.policies {
+			if strings.HasPrefix(aws.StringValue(p.policy.Path),
+				aws.StringValue(in.PathPrefix)) {
+				out.Policies = append(out.Policies, p.policy)
+			}
+		}
+		sort.Slice(out.Policies, func(i, j int) bool {
+			return *out.Policies[i].Arn < *out.Policies[j].Arn
+		})
+		q.Data = out
+	case *iam.ListEntitiesForPolicyInput:
+		if p := policy(in.PolicyArn); p != nil {
+			out := &iam.ListEntitiesForPolicyOutput{
+				IsTruncated: aws.Bool(false),
+			}
+			r := aws.StringValue(in.PolicyArn)
+			for name, u := range m.users {
+				if u.attached[r] {
+					out.PolicyUsers = append(out.PolicyUsers,
+						iam.PolicyUser{UserName: aws.String(name)})
+				}
+			}
+			for name, g := range m.groups {
+				if g.attached[r] {
+					out.PolicyGroups = append(out.PolicyGroups,
+						iam.PolicyGroup{GroupName: aws.String(name)})
+				}
+			}
+			for name, ro := range m.roles {
+				if ro.attached[r] {
+					out.PolicyRoles = append(out.PolicyRoles,
+						iam.PolicyRole{RoleName: aws.String(name)})
+				}
+			}
+			q.Data = out
+		}
+	case *iam.DeletePolicyInput:
+		if p := policy(in.PolicyArn); p != nil {
+			r := aws.StringValue(in.PolicyArn)
+			attached := len(p.versions) > 1
+			for _, u := range m.users {
+				attached = attached || u.attached[r]
+			}
+			for _, g := range m.groups {
+				attached = attached || g.attached[r]
+			}
+			for _, ro := range m.roles {
+				attached = attached || ro.attached[r]
+			}
+			if attached {
+				q.Error = deleteConflict()
+				return true
+			}
+			delete(m.policies, r)
+			q.Data = &iam.DeletePolicyOutput{}
+		}
+	default:
+		return false
+	}
+	return true
+}
+
+// addVersion adds a new default policy version.
+func (p *mockPolicy) addVersion(doc *string) *iam.PolicyVersion {
+	for i := range p.versions {
+		p.versions[i].IsDefaultVersion = aws.Bool(false)
+	}
+	p.next++
+	id := "v" + strconv.Itoa(p.next)
+	p.versions = append(p.versions, iam.PolicyVersion{
+		CreateDate:       aws.Time(time.Now().Add(time.Duration(p.next))),
+		Document:         doc,
+		IsDefaultVersion: aws.Bool(true),
+		VersionId:        aws.String(id),
+	})
+	p.policy.DefaultVersionId = aws.String(id)
+	return &p.versions[len(p.versions)-1]
+}
+
+// groupsFor returns the names of all groups containing user.
+func (m *mockIAM) groupsFor(user string) []string {
+	var names []string
+	for name, g := range m.groups {
+		if g.users[user] {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// mapKeys returns the sorted keys of set m.
+func mapKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
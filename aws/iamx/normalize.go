@@ -0,0 +1,226 @@
+package iamx
+
+import (
+	"encoding/json"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Normalize returns a normalized copy of policy p. All multi-value elements
+// are sorted and deduplicated, action service prefixes are converted to lower
+// case, entries that are covered by a wildcard entry in the same element are
+// removed, and statements that differ only in their Action or Resource
+// elements are merged. Statements with identical resources are merged first,
+// followed by those with identical actions, until no more merges are possible.
+// The merged statements do not depend on the original statement order. The
+// normalized policy grants the same permissions as the original.
+func (p *Policy) Normalize() *Policy {
+	c := p.Clone()
+	for _, s := range c.Statement {
+		s.normalize()
+	}
+	for n := -1; n != len(c.Statement); {
+		n = len(c.Statement)
+		c.Statement = mergeStatements(c.Statement, stmtAction)
+		c.Statement = mergeStatements(c.Statement, stmtResource)
+	}
+	return c
+}
+
+// Canonical returns the canonical JSON representation of policy p, which is
+// suitable for hashing and comparison. Two policies with equivalent normalized
+// forms have identical canonical representations, regardless of the original
+// order of their statements and values.
+func (p *Policy) Canonical() *string {
+	c := p.Normalize()
+	keys := make([]string, len(c.Statement))
+	for i, s := range c.Statement {
+		b, err := json.Marshal(s)
+		if err != nil {
+			panic("policy: encode error: " + err.Error())
+		}
+		keys[i] = string(b)
+	}
+	sort.Sort(stmtSorter{c.Statement, keys})
+	return c.Doc()
+}
+
+// normalize normalizes all statement elements in place.
+func (s *Statement) normalize() {
+	s.Principal.normalize()
+	s.NotPrincipal.normalize()
+	s.Action = s.Action.normalizeActions()
+	s.NotAction = s.NotAction.normalizeActions()
+	s.Resource = s.Resource.normalizeResources()
+	s.NotResource = s.NotResource.normalizeResources()
+	for _, conds := range s.Condition {
+		for k, v := range conds {
+			conds[k] = v.normalize()
+		}
+	}
+}
+
+// Indices of Action and Resource sets in stmtSets.all().
+const (
+	stmtAction   = 2
+	stmtResource = 4
+)
+
+// mergeStatements merges statements that have the same SID and differ only in
+// the element identified by elem, which must be stmtAction or stmtResource.
+// Each merged statement takes the position of the first statement in its
+// group. Identical statements are always merged.
+func mergeStatements(ss []*Statement, elem int) []*Statement {
+	groups := make(map[string]*Statement, len(ss))
+	out := make([]*Statement, 0, len(ss))
+	for _, s := range ss {
+		k := s.mergeKey(elem)
+		g := groups[k]
+		if g == nil {
+			groups[k] = s
+			out = append(out, s)
+			continue
+		}
+		if elem == stmtAction {
+			g.Action = append(g.Action, s.Action...).normalizeActions()
+		} else {
+			g.Resource = append(g.Resource, s.Resource...).normalizeResources()
+		}
+	}
+	return out
+}
+
+// mergeKey returns a key that is shared by all statements that can be merged
+// with s by combining their elem elements. If s does not have the elem element,
+// the key includes all of its contents.
+func (s *Statement) mergeKey(elem int) string {
+	if elem == stmtAction && s.Action == nil ||
+		elem == stmtResource && s.Resource == nil {
+		elem = -1
+	}
+	var b strings.Builder
+	b.WriteString(strconv.Itoa(elem))
+	b.WriteByte(0)
+	b.WriteString(s.SID)
+	b.WriteByte(0)
+	b.WriteString(string(s.Effect))
+	for i, v := range newStmtSets(s).all() {
+		b.WriteByte(0)
+		if i != elem {
+			b.WriteString(strings.Join(v, "\x01"))
+		}
+	}
+	return b.String()
+}
+
+// normalize sorts and deduplicates all principal values in place.
+func (p *Principal) normalize() {
+	if p != nil {
+		p.AWS = p.AWS.normalize()
+		p.Federated = p.Federated.normalize()
+		p.Service = p.Service.normalize()
+	}
+}
+
+// normalize returns a sorted copy of v without duplicates, preserving the
+// distinction between nil and empty values.
+func (v PolicyMultiVal) normalize() PolicyMultiVal {
+	if v == nil {
+		return nil
+	}
+	if s := toSet(v); s != nil {
+		return PolicyMultiVal(s)
+	}
+	return PolicyMultiVal{}
+}
+
+// normalizeActions normalizes action names and removes redundant entries.
+// Known actions are converted to their catalog names. Since action names are
+// case-insensitive, only the first of several entries that differ only in
+// case is kept.
+func (v PolicyMultiVal) normalizeActions() PolicyMultiVal {
+	if v == nil {
+		return nil
+	}
+	c := make(PolicyMultiVal, len(v))
+	for i, a := range v {
+		if info := LookupAction(a); info != nil {
+			a = info.String()
+		} else if j := strings.IndexByte(a, ':'); j > 0 {
+			a = strings.ToLower(a[:j]) + a[j:]
+		}
+		c[i] = a
+	}
+	c = c.normalize()
+	seen := make(map[string]bool, len(c))
+	uniq := c[:0]
+	for _, a := range c {
+		if k := strings.ToLower(a); !seen[k] {
+			seen[k] = true
+			uniq = append(uniq, a)
+		}
+	}
+	return uniq.dropCovered(func(pat, s string) bool {
+		return wildcardMatch(pat, s, true)
+	})
+}
+
+// normalizeResources normalizes resource names and removes redundant entries.
+func (v PolicyMultiVal) normalizeResources() PolicyMultiVal {
+	if v == nil {
+		return nil
+	}
+	return v.normalize().dropCovered(func(pat, s string) bool {
+		return PolicyMultiVal{pat}.matchResource(s)
+	})
+}
+
+// matchFunc returns true if s matches pattern pat.
+type matchFunc func(pat, s string) bool
+
+// dropCovered returns a copy of v without entries that are covered by another
+// wildcard entry according to the match function. If two entries cover each
+// other, the first one is kept.
+func (v PolicyMultiVal) dropCovered(fn matchFunc) PolicyMultiVal {
+	out := make(PolicyMultiVal, 0, len(v))
+	for i, s := range v {
+		covered := false
+		for j, pat := range v {
+			if i != j && covers(pat, s, fn) && (j < i || !covers(s, pat, fn)) {
+				covered = true
+				break
+			}
+		}
+		if !covered {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// covers returns true if every value matched by pattern s is also matched by
+// pattern pat. A '?' in pat may consume a '*' in s, so such cases are rejected.
+// Identical patterns do not cover each other.
+func covers(pat, s string, fn matchFunc) bool {
+	if pat == s || strings.IndexAny(pat, "*?") == -1 {
+		return false
+	}
+	if strings.IndexByte(pat, '?') != -1 && strings.IndexByte(s, '*') != -1 {
+		return false
+	}
+	return fn(pat, s)
+}
+
+// stmtSorter sorts statements by their keys.
+type stmtSorter struct {
+	s    []*Statement
+	keys []string
+}
+
+func (s stmtSorter) Len() int           { return len(s.s) }
+func (s stmtSorter) Less(i, j int) bool { return s.keys[i] < s.keys[j] }
+func (s stmtSorter) Swap(i, j int) {
+	s.s[i], s.s[j] = s.s[j], s.s[i]
+	s.keys[i], s.keys[j] = s.keys[j], s.keys[i]
+}
@@ -0,0 +1,185 @@
+package iamx
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalize(t *testing.T) {
+	doc := `{"Statement": [{
+		"Effect": "Allow",
+		"Principal": {"AWS": ["b", "a", "b"]},
+		"Action": ["S3:GetObject", "s3:Get*", "s3:ListBucket", "s3:get*"],
+		"Resource": ["arn:aws:s3:::b/x", "arn:aws:s3:::b/*", "arn:aws:s3:::c"]
+	},{
+		"Effect": "Allow",
+		"Principal": {"AWS": ["a", "b"]},
+		"Action": "s3:PutObject",
+		"Resource": ["arn:aws:s3:::c", "arn:aws:s3:::b/*"]
+	},{
+		"Effect": "Allow",
+		"Principal": {"AWS": ["a", "b"]},
+		"Action": "s3:PutObject",
+		"Resource": "arn:aws:s3:::d"
+	},{
+		"Effect": "Deny",
+		"NotAction": ["iam:*", "iam:Get*"],
+		"Resource": "*",
+		"Condition": {"StringEquals": {"k": ["y", "x", "x"]}}
+	},{
+		"Sid": "X",
+		"Effect": "Deny",
+		"NotAction": "iam:*",
+		"Resource": "*"
+	}]}`
+	p, err := ParsePolicy(&doc)
+	require.NoError(t, err)
+	n := p.Normalize()
+	require.Len(t, n.Statement, 4)
+
+	s := n.Statement[0]
+	assert.Equal(t, PolicyMultiVal{"a", "b"}, s.Principal.AWS)
+	assert.Equal(t, PolicyMultiVal{"s3:Get*", "s3:ListBucket", "s3:PutObject"},
+		s.Action)
+	assert.Equal(t, PolicyMultiVal{"arn:aws:s3:::b/*", "arn:aws:s3:::c"},
+		s.Resource)
+
+	s = n.Statement[1]
+	assert.Equal(t, PolicyMultiVal{"s3:PutObject"}, s.Action)
+	assert.Equal(t, PolicyMultiVal{"arn:aws:s3:::d"}, s.Resource)
+
+	s = n.Statement[2]
+	assert.Equal(t, PolicyMultiVal{"iam:*"}, s.NotAction)
+	assert.Equal(t, PolicyMultiVal{"x", "y"}, s.Condition["StringEquals"]["k"])
+	assert.Equal(t, "X", n.Statement[3].SID)
+
+	// Original is not modified
+	assert.Len(t, p.Statement, 5)
+	assert.Equal(t, "S3:GetObject", p.Statement[0].Action[0])
+
+	// Normalization preserves permissions
+	for _, q := range []*Request{
+		{Action: "s3:GetObject", Resource: "arn:aws:s3:::b/x", Principal: "a"},
+		{Action: "s3:PutObject", Resource: "arn:aws:s3:::c", Principal: "b"},
+		{Action: "s3:PutObject", Resource: "arn:aws:s3:::e", Principal: "b"},
+		{Action: "s3:ListBucket", Resource: "arn:aws:s3:::d", Principal: "a"},
+		{Action: "iam:GetRole", Resource: "x", Principal: "a"},
+		{Action: "ec2:RunInstances", Resource: "x", Principal: "a",
+			Context: Context{"k": {"x"}}},
+	} {
+		d1, _, err := Eval(q, p)
+		require.NoError(t, err)
+		d2, _, err := Eval(q, n)
+		require.NoError(t, err)
+		assert.Equal(t, d1, d2, "%+v", q)
+	}
+}
+
+func TestNormalizeActionCase(t *testing.T) {
+	v := PolicyMultiVal{"s3:getobject", "S3:GetObject", "foo:bar", "FOO:Bar",
+		"foo:Bar", "sqs:sendmessage"}
+	assert.Equal(t, PolicyMultiVal{"foo:Bar", "s3:GetObject", "sqs:SendMessage"},
+		v.normalizeActions())
+	p := &Policy{Statement: []*Statement{{
+		Effect:   Allow,
+		Action:   PolicyMultiVal{"s3:GetObject", "s3:getobject"},
+		Resource: PolicyMultiVal{"*"},
+	}}}
+	assert.Equal(t, `{"Version":"2012-10-17","Statement":[{"Effect":"Allow",`+
+		`"Action":"s3:GetObject","Resource":"*"}]}`, *p.Canonical())
+}
+
+func TestNormalizeOrder(t *testing.T) {
+	stmts := []*Statement{{
+		Effect:   Allow,
+		Action:   PolicyMultiVal{"s3:GetObject"},
+		Resource: PolicyMultiVal{"arn:aws:s3:::b/1"},
+	}, {
+		Effect:   Allow,
+		Action:   PolicyMultiVal{"s3:PutObject"},
+		Resource: PolicyMultiVal{"arn:aws:s3:::b/1"},
+	}, {
+		Effect:   Allow,
+		Action:   PolicyMultiVal{"s3:GetObject"},
+		Resource: PolicyMultiVal{"arn:aws:s3:::b/2"},
+	}, {
+		Effect:   Allow,
+		Action:   PolicyMultiVal{"s3:GetObject"},
+		Resource: PolicyMultiVal{"arn:aws:s3:::b/1"},
+	}}
+	want := `{"Version":"2012-10-17","Statement":[` +
+		`{"Effect":"Allow","Action":"s3:GetObject","Resource":"arn:aws:s3:::b/2"},` +
+		`{"Effect":"Allow","Action":["s3:GetObject","s3:PutObject"],` +
+		`"Resource":"arn:aws:s3:::b/1"}]}`
+	var permute func(k int)
+	permute = func(k int) {
+		if k == len(stmts) {
+			p := &Policy{Statement: append([]*Statement(nil), stmts...)}
+			assert.Equal(t, want, *p.Canonical())
+			return
+		}
+		for i := k; i < len(stmts); i++ {
+			stmts[k], stmts[i] = stmts[i], stmts[k]
+			permute(k + 1)
+			stmts[k], stmts[i] = stmts[i], stmts[k]
+		}
+	}
+	permute(0)
+}
+
+func TestCanonical(t *testing.T) {
+	a := `{"Version": "2012-10-17", "Statement": [{
+		"Effect": "Deny",
+		"Action": ["ec2:*", "EC2:RunInstances"],
+		"Resource": "*"
+	},{
+		"Effect": "Allow",
+		"Action": ["s3:PutObject", "s3:GetObject"],
+		"Resource": "*"
+	}]}`
+	b := `{"Statement": [{
+		"Effect": "Allow",
+		"Action": "s3:GetObject",
+		"Resource": "*"
+	},{
+		"Effect": "Allow",
+		"Action": "s3:PutObject",
+		"Resource": "*"
+	},{
+		"Effect": "Deny",
+		"Action": "ec2:*",
+		"Resource": "*"
+	}]}`
+	pa, err := ParsePolicy(&a)
+	require.NoError(t, err)
+	pb, err := ParsePolicy(&b)
+	require.NoError(t, err)
+	want := `{"Version":"2012-10-17","Statement":[` +
+		`{"Effect":"Allow","Action":["s3:GetObject","s3:PutObject"],"Resource":"*"},` +
+		`{"Effect":"Deny","Action":"ec2:*","Resource":"*"}]}`
+	assert.Equal(t, want, *pa.Canonical())
+	assert.Equal(t, want, *pb.Canonical())
+}
+
+func TestCovers(t *testing.T) {
+	fn := func(pat, s string) bool { return wildcardMatch(pat, s, false) }
+	tests := []*struct {
+		pat, s string
+		want   bool
+	}{
+		{"*", "a", true},
+		{"*", "*", false},
+		{"a*", "ab*", true},
+		{"a*", "a?", true},
+		{"a?", "a*", false},
+		{"a?", "a?", false},
+		{"a?", "ab", true},
+		{"a", "a", false},
+		{"ab", "a?", false},
+	}
+	for _, tc := range tests {
+		assert.Equal(t, tc.want, covers(tc.pat, tc.s, fn), "%+v", tc)
+	}
+}
@@ -0,0 +1,93 @@
+package iamx
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/mxk/go-cloud/aws/arn"
+	"github.com/mxk/go-cloud/aws/region"
+)
+
+// Translate returns a copy of policy p converted to the target partition of t.
+// It rewrites ARNs in Resource, NotResource, Principal, NotPrincipal, and
+// Condition values, service principal names, and aws:RequestedRegion condition
+// values. ARNs of AWS managed policies are only translated if the policies are
+// listed in t.ManagedPolicies. Values that cannot be translated are left
+// unmodified and reported, in sorted order, via *region.UnmappedError. The
+// translated copy is returned even if some values could not be mapped.
+func (p *Policy) Translate(t *region.Translator) (*Policy, error) {
+	x := partTranslator{t: t}
+	c := p.Clone()
+	for _, s := range c.Statement {
+		x.principal(s.Principal)
+		x.principal(s.NotPrincipal)
+		x.arns(s.Resource)
+		x.arns(s.NotResource)
+		for _, conds := range s.Condition {
+			for k, v := range conds {
+				if strings.EqualFold(k, "aws:RequestedRegion") {
+					x.regions(v)
+				} else {
+					x.arns(v)
+				}
+			}
+		}
+	}
+	if len(x.unmapped) > 0 {
+		sort.Strings(x.unmapped)
+		return c, &region.UnmappedError{
+			Partition: t.Partition,
+			Values:    x.unmapped,
+		}
+	}
+	return c, nil
+}
+
+// partTranslator applies a region.Translator to policy values, recording all
+// values that cannot be translated.
+type partTranslator struct {
+	t        *region.Translator
+	unmapped []string
+}
+
+// principal translates AWS and federated principal ARNs and service names.
+func (x *partTranslator) principal(p *Principal) {
+	if p == nil {
+		return
+	}
+	x.arns(p.AWS)
+	x.arns(p.Federated)
+	for i, s := range p.Service {
+		if s != "*" {
+			v, ok := x.t.ServicePrincipal(s)
+			x.set(p.Service, i, v, ok)
+		}
+	}
+}
+
+// arns translates all ARN values in v. Other values are ignored.
+func (x *partTranslator) arns(v PolicyMultiVal) {
+	for i, s := range v {
+		if strings.HasPrefix(s, "arn:") {
+			r, ok := x.t.ARN(arn.ARN(s))
+			x.set(v, i, string(r), ok)
+		}
+	}
+}
+
+// regions translates all region names in v.
+func (x *partTranslator) regions(v PolicyMultiVal) {
+	for i, s := range v {
+		r, ok := x.t.Region(s)
+		x.set(v, i, r, ok)
+	}
+}
+
+// set updates v[i] if ok is true, and records v[i] as unmapped otherwise.
+func (x *partTranslator) set(v PolicyMultiVal, i int, s string, ok bool) {
+	if ok {
+		v[i] = s
+	} else {
+		x.unmapped = append(x.unmapped, v[i])
+	}
+}
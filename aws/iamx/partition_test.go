@@ -0,0 +1,77 @@
+package iamx
+
+import (
+	"testing"
+
+	"github.com/mxk/go-cloud/aws/region"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTranslate(t *testing.T) {
+	doc := `{
+		"Statement": [{
+			"Effect": "Allow",
+			"Principal": {
+				"AWS": ["arn:aws:iam::123456789012:root", "123456789012"],
+				"Service": ["ec2.amazonaws.com", "*"]
+			},
+			"Action": "sts:AssumeRole"
+		},{
+			"Effect": "Allow",
+			"Action": "sqs:*",
+			"Resource": [
+				"arn:aws:sqs:us-east-1:123456789012:q",
+				"arn:aws:sqs:*:123456789012:q",
+				"arn:aws:iam::aws:policy/ReadOnlyAccess",
+				"*"
+			],
+			"Condition": {
+				"StringEquals": {"aws:RequestedRegion": "us-east-1"},
+				"ArnLike": {"aws:SourceArn": "arn:aws:sns:us-east-1:*:t"}
+			}
+		}]
+	}`
+	p, err := ParsePolicy(&doc)
+	require.NoError(t, err)
+	tr := &region.Translator{
+		Partition:       "aws-cn",
+		Regions:         map[string]string{"us-east-1": "cn-north-1"},
+		ManagedPolicies: map[string]bool{"ReadOnlyAccess": true},
+	}
+	c, err := p.Translate(tr)
+	require.NoError(t, err)
+	assert.Equal(t, PolicyMultiVal{"arn:aws-cn:iam::123456789012:root",
+		"123456789012"}, c.Statement[0].Principal.AWS)
+	assert.Equal(t, PolicyMultiVal{"ec2.amazonaws.com.cn", "*"},
+		c.Statement[0].Principal.Service)
+	s := c.Statement[1]
+	assert.Equal(t, PolicyMultiVal{
+		"arn:aws-cn:sqs:cn-north-1:123456789012:q",
+		"arn:aws-cn:sqs:*:123456789012:q",
+		"arn:aws-cn:iam::aws:policy/ReadOnlyAccess",
+		"*",
+	}, s.Resource)
+	assert.Equal(t, PolicyMultiVal{"cn-north-1"},
+		s.Condition["StringEquals"]["aws:RequestedRegion"])
+	assert.Equal(t, PolicyMultiVal{"arn:aws-cn:sns:cn-north-1:*:t"},
+		s.Condition["ArnLike"]["aws:SourceArn"])
+	assert.Equal(t, "arn:aws:sqs:us-east-1:123456789012:q",
+		p.Statement[1].Resource[0])
+
+	tr.Regions, tr.ManagedPolicies = nil, nil
+	c, err = p.Translate(tr)
+	assert.Equal(t, &region.UnmappedError{
+		Partition: "aws-cn",
+		Values: []string{
+			"arn:aws:iam::aws:policy/ReadOnlyAccess",
+			"arn:aws:sns:us-east-1:*:t",
+			"arn:aws:sqs:us-east-1:123456789012:q",
+			"us-east-1",
+		},
+	}, err)
+	assert.Equal(t, "arn:aws-cn:iam::123456789012:root",
+		c.Statement[0].Principal.AWS[0])
+	assert.Equal(t, "arn:aws:sqs:us-east-1:123456789012:q",
+		c.Statement[1].Resource[0])
+}
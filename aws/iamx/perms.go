@@ -0,0 +1,302 @@
+package iamx
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+	"github.com/mxk/go-cloud/aws/arn"
+	"github.com/mxk/go-fast"
+)
+
+// PolicySource identifies how a policy is applied to a user or role.
+type PolicySource string
+
+// Policy sources.
+const (
+	SourceInline       = PolicySource("inline")
+	SourceManaged      = PolicySource("managed")
+	SourceGroupInline  = PolicySource("group-inline")
+	SourceGroupManaged = PolicySource("group-managed")
+	SourceBoundary     = PolicySource("boundary")
+)
+
+// AppliedPolicy is a policy that applies to a user or role. Name is the name
+// of an inline policy or the ARN of a managed policy. Version is the default
+// version of a managed policy. Group is set for group policies.
+type AppliedPolicy struct {
+	Source  PolicySource
+	Name    string
+	Version string `json:",omitempty"`
+	Group   string `json:",omitempty"`
+	Policy  *Policy
+}
+
+// String implements fmt.Stringer.
+func (p *AppliedPolicy) String() string {
+	s := string(p.Source) + " " + p.Name
+	if p.Group != "" {
+		s += " (group " + p.Group + ")"
+	}
+	return s
+}
+
+// SourcedStatement is a policy statement annotated with the policy that
+// contains it. Index is the position of the statement within that policy.
+type SourcedStatement struct {
+	Source    *AppliedPolicy
+	Index     int
+	Statement *Statement
+}
+
+// String implements fmt.Stringer.
+func (s *SourcedStatement) String() string {
+	return s.Source.String() + " $.Statement[" + strconv.Itoa(s.Index) + "]"
+}
+
+// Permissions contains all policies that determine the effective permissions
+// of a user or role. Policies contains identity-based policies in the order
+// of inline, managed, and then group policies. Boundary is nil if the entity
+// does not have a permissions boundary.
+type Permissions struct {
+	ARN      arn.ARN
+	Policies []*AppliedPolicy
+	Boundary *AppliedPolicy `json:",omitempty"`
+}
+
+// Statements returns all identity-based policy statements followed by the
+// permissions boundary statements.
+func (p *Permissions) Statements() []*SourcedStatement {
+	var all []*SourcedStatement
+	add := func(ap *AppliedPolicy) {
+		for i, s := range ap.Policy.Statement {
+			all = append(all, &SourcedStatement{ap, i, s})
+		}
+	}
+	for _, ap := range p.Policies {
+		add(ap)
+	}
+	if p.Boundary != nil {
+		add(p.Boundary)
+	}
+	return all
+}
+
+// Eval evaluates request q against the identity-based policies and the
+// permissions boundary. An allow requires an allow statement in both the
+// identity-based policies and the boundary, if there is one. An explicit
+// deny in either overrides all allows. The returned statement is the one that
+// determined the decision, or nil for an implicit deny. Statements of managed
+// policies that are applied more than once are attributed to the first one.
+func (p *Permissions) Eval(q *Request) (Decision, *SourcedStatement, error) {
+	all := p.Statements()
+	src := make(map[*Statement]*SourcedStatement, len(all))
+	for _, s := range all {
+		if src[s.Statement] == nil {
+			src[s.Statement] = s
+		}
+	}
+	pols := make([]*Policy, len(p.Policies))
+	for i, ap := range p.Policies {
+		pols[i] = ap.Policy
+	}
+	d, s, err := Eval(q, pols...)
+	if err != nil || d == ExplicitDeny || p.Boundary == nil {
+		return d, src[s], err
+	}
+	bd, bs, err := Eval(q, p.Boundary.Policy)
+	switch {
+	case err != nil || bd == ExplicitDeny:
+		return bd, src[bs], err
+	case bd == ImplicitDeny:
+		return ImplicitDeny, nil, nil
+	}
+	return d, src[s], nil
+}
+
+// RolePermissions returns all policies that apply to the specified role.
+func (c Client) RolePermissions(role string) (*Permissions, error) {
+	in := iam.GetRoleInput{RoleName: aws.String(role)}
+	out, err := c.GetRoleRequest(&in).Send()
+	if err != nil {
+		return nil, err
+	}
+	perms := &Permissions{ARN: arn.Value(out.Role.Arn)}
+	if b := out.Role.PermissionsBoundary; b != nil {
+		perms.Boundary = &AppliedPolicy{
+			Source: SourceBoundary,
+			Name:   aws.StringValue(b.PermissionsBoundaryArn),
+		}
+	}
+	var inline, managed []string
+	err = fast.Call(
+		func() (err error) {
+			inline, err = c.rolePolicyNames(role)
+			return
+		},
+		func() (err error) {
+			managed, err = c.attachedRolePolicies(role)
+			return
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	perms.add(SourceInline, "", inline)
+	perms.add(SourceManaged, "", managed)
+	return perms, c.loadPolicies(perms, func(name string) (*string, error) {
+		in := iam.GetRolePolicyInput{
+			PolicyName: aws.String(name),
+			RoleName:   aws.String(role),
+		}
+		out, err := c.GetRolePolicyRequest(&in).Send()
+		if err != nil {
+			return nil, err
+		}
+		return out.PolicyDocument, nil
+	})
+}
+
+// UserPermissions returns all policies that apply to the specified user,
+// including the policies of all groups containing the user.
+func (c Client) UserPermissions(user string) (*Permissions, error) {
+	in := iam.GetUserInput{UserName: aws.String(user)}
+	out, err := c.GetUserRequest(&in).Send()
+	if err != nil {
+		return nil, err
+	}
+	perms := &Permissions{ARN: arn.Value(out.User.Arn)}
+	if b := out.User.PermissionsBoundary; b != nil {
+		perms.Boundary = &AppliedPolicy{
+			Source: SourceBoundary,
+			Name:   aws.StringValue(b.PermissionsBoundaryArn),
+		}
+	}
+	var inline, managed, groups []string
+	err = fast.Call(
+		func() (err error) {
+			inline, err = c.userPolicyNames(user)
+			return
+		},
+		func() (err error) {
+			managed, err = c.attachedUserPolicies(user)
+			return
+		},
+		func() (err error) {
+			groups, err = c.userGroups(user)
+			return
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	perms.add(SourceInline, "", inline)
+	perms.add(SourceManaged, "", managed)
+	groups = toSet(groups)
+	groupInline := make([][]string, len(groups))
+	groupManaged := make([][]string, len(groups))
+	err = fast.ForEachIO(len(groups), func(i int) error {
+		return fast.Call(
+			func() (err error) {
+				groupInline[i], err = c.groupPolicyNames(groups[i])
+				return
+			},
+			func() (err error) {
+				groupManaged[i], err = c.attachedGroupPolicies(groups[i])
+				return
+			},
+		)
+	})
+	if err != nil {
+		return nil, err
+	}
+	for i, g := range groups {
+		perms.add(SourceGroupInline, g, groupInline[i])
+		perms.add(SourceGroupManaged, g, groupManaged[i])
+	}
+	return perms, c.loadPolicies(perms, func(name string) (*string, error) {
+		in := iam.GetUserPolicyInput{
+			PolicyName: aws.String(name),
+			UserName:   aws.String(user),
+		}
+		out, err := c.GetUserPolicyRequest(&in).Send()
+		if err != nil {
+			return nil, err
+		}
+		return out.PolicyDocument, nil
+	})
+}
+
+// add appends policies with the specified names to p in sorted order.
+func (p *Permissions) add(src PolicySource, group string, names []string) {
+	for _, name := range toSet(names) {
+		p.Policies = append(p.Policies, &AppliedPolicy{
+			Source: src,
+			Name:   name,
+			Group:  group,
+		})
+	}
+}
+
+// loadPolicies fetches and decodes the documents of all policies in p. Inline
+// policies of the user or role are fetched by getInline. Managed policies that
+// are applied more than once are fetched only once.
+func (c Client) loadPolicies(p *Permissions,
+	getInline func(name string) (*string, error)) error {
+	all := p.Policies
+	if p.Boundary != nil {
+		all = append(all[:len(all):len(all)], p.Boundary)
+	}
+	managed := make(map[string][]*AppliedPolicy)
+	var load []*AppliedPolicy
+	for _, ap := range all {
+		switch ap.Source {
+		case SourceManaged, SourceGroupManaged, SourceBoundary:
+			if managed[ap.Name] == nil {
+				load = append(load, ap)
+			}
+			managed[ap.Name] = append(managed[ap.Name], ap)
+		default:
+			load = append(load, ap)
+		}
+	}
+	return fast.ForEachIO(len(load), func(i int) error {
+		ap := load[i]
+		var doc *string
+		var err error
+		switch ap.Source {
+		case SourceInline:
+			doc, err = getInline(ap.Name)
+		case SourceGroupInline:
+			in := iam.GetGroupPolicyInput{
+				GroupName:  aws.String(ap.Group),
+				PolicyName: aws.String(ap.Name),
+			}
+			var out *iam.GetGroupPolicyOutput
+			if out, err = c.GetGroupPolicyRequest(&in).Send(); err == nil {
+				doc = out.PolicyDocument
+			}
+		default:
+			r := arn.ARN(ap.Name)
+			in := iam.GetPolicyInput{PolicyArn: arn.String(r)}
+			var out *iam.GetPolicyOutput
+			if out, err = c.GetPolicyRequest(&in).Send(); err == nil {
+				ap.Version = aws.StringValue(out.Policy.DefaultVersionId)
+				doc, err = c.policyVersion(r, ap.Version)
+			}
+		}
+		if err != nil {
+			return err
+		}
+		pol, err := ParsePolicy(doc)
+		if err != nil {
+			return fmt.Errorf("iamx: invalid %v policy (%v)", ap, err)
+		}
+		ap.Policy = pol
+		for _, m := range managed[ap.Name] {
+			m.Version, m.Policy = ap.Version, pol
+		}
+		return nil
+	})
+}
@@ -0,0 +1,136 @@
+package iamx
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+	"github.com/mxk/go-cloud/aws/arn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUserPermissions(t *testing.T) {
+	m := newMockIAM()
+	c := m.client()
+	allow := func(action, resource string) *Policy {
+		return &Policy{Statement: []*Statement{{
+			Effect:   Allow,
+			Action:   PolicyMultiVal{action},
+			Resource: PolicyMultiVal{resource},
+		}}}
+	}
+	s3 := "arn:aws:iam::123456789012:policy/s3"
+	boundary := "arn:aws:iam::123456789012:policy/boundary"
+	require.NoError(t, c.EnsurePolicy(arn.ARN(s3), allow("s3:*", "*")))
+	require.NoError(t, c.EnsurePolicy(arn.ARN(boundary), &Policy{
+		Statement: []*Statement{
+			{
+				Effect:   Allow,
+				Action:   PolicyMultiVal{"s3:*", "sqs:*"},
+				Resource: PolicyMultiVal{"*"},
+			},
+			{
+				Effect:   Deny,
+				Action:   PolicyMultiVal{"s3:DeleteBucket"},
+				Resource: PolicyMultiVal{"*"},
+			},
+		},
+	}))
+	u := m.addUser("/", "u")
+	u.inline["sqs"] = *allow("sqs:SendMessage", "*").Doc()
+	u.inline["ec2"] = *allow("ec2:*", "*").Doc()
+	u.attached[s3] = true
+	u.user.PermissionsBoundary = &iam.AttachedPermissionsBoundary{
+		PermissionsBoundaryArn: aws.String(boundary),
+	}
+	g := m.addGroup("/", "g", "u")
+	g.inline["deny"] = *(&Policy{Statement: []*Statement{{
+		Effect:   Deny,
+		Action:   PolicyMultiVal{"sqs:SendMessage"},
+		Resource: PolicyMultiVal{"arn:aws:sqs:*:*:prod-*"},
+	}}}).Doc()
+	g.attached[s3] = true
+	m.addGroup("/", "other")
+	m.mutations()
+
+	p, err := c.UserPermissions("u")
+	require.NoError(t, err)
+	assert.Empty(t, m.mutations())
+	assert.Equal(t, arn.ARN("arn:aws:iam::123456789012:user/u"), p.ARN)
+	var srcs []string
+	for _, ap := range p.Policies {
+		srcs = append(srcs, ap.String())
+	}
+	assert.Equal(t, []string{
+		"inline ec2",
+		"inline sqs",
+		"managed " + s3,
+		"group-inline deny (group g)",
+		"group-managed " + s3 + " (group g)",
+	}, srcs)
+	assert.Equal(t, "v1", p.Policies[2].Version)
+	assert.True(t, p.Policies[2].Policy == p.Policies[4].Policy)
+	require.NotNil(t, p.Boundary)
+	assert.Equal(t, boundary, p.Boundary.Name)
+	assert.Len(t, p.Statements(), 7)
+
+	tests := []*struct {
+		action   string
+		resource string
+		want     Decision
+		src      string
+	}{
+		{"s3:GetObject", "arn:aws:s3:::b/k", Allowed,
+			"managed " + s3 + " $.Statement[0]"},
+		{"s3:DeleteBucket", "arn:aws:s3:::b", ExplicitDeny,
+			"boundary " + boundary + " $.Statement[1]"},
+		{"sqs:SendMessage", "arn:aws:sqs:us-east-1:123456789012:q", Allowed,
+			"inline sqs $.Statement[0]"},
+		{"sqs:SendMessage", "arn:aws:sqs:us-east-1:123456789012:prod-q",
+			ExplicitDeny, "group-inline deny (group g) $.Statement[0]"},
+		{"ec2:RunInstances", "*", ImplicitDeny, ""},
+		{"iam:PassRole", "*", ImplicitDeny, ""},
+	}
+	for _, tc := range tests {
+		d, s, err := p.Eval(&Request{
+			Action:   tc.action,
+			Resource: tc.resource,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, tc.want, d, "%+v", tc)
+		if tc.src == "" {
+			assert.Nil(t, s, "%+v", tc)
+		} else if assert.NotNil(t, s, "%+v", tc) {
+			assert.Equal(t, tc.src, s.String())
+		}
+	}
+}
+
+func TestRolePermissions(t *testing.T) {
+	m := newMockIAM()
+	c := m.client()
+	trust := AssumeRolePolicy(Allow, "123456789012")
+	r := m.addRole("/", "r", trust)
+	r.inline["p"] = *(&Policy{Statement: []*Statement{{
+		Effect:   Allow,
+		Action:   PolicyMultiVal{"logs:*"},
+		Resource: PolicyMultiVal{"*"},
+	}}}).Doc()
+	r.attached["arn:aws:iam::123456789012:policy/missing"] = true
+
+	_, err := c.RolePermissions("r")
+	assert.Error(t, err)
+
+	delete(r.attached, "arn:aws:iam::123456789012:policy/missing")
+	p, err := c.RolePermissions("r")
+	require.NoError(t, err)
+	assert.Equal(t, arn.ARN("arn:aws:iam::123456789012:role/r"), p.ARN)
+	require.Len(t, p.Policies, 1)
+	assert.Equal(t, SourceInline, p.Policies[0].Source)
+	assert.Nil(t, p.Boundary)
+	d, s, err := p.Eval(&Request{Action: "logs:PutLogEvents", Resource: "*"})
+	require.NoError(t, err)
+	assert.Equal(t, Allowed, d)
+	assert.Equal(t, "inline p $.Statement[0]", s.String())
+}
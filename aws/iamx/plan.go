@@ -0,0 +1,348 @@
+package iamx
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+	"github.com/mxk/go-fast"
+)
+
+// DeletePlan lists all IAM resources that would be removed by deleting users
+// and/or roles under an IAM path. A plan can be serialized for review and
+// executed later by ApplyDeletePlan. Filtered is set if the plan was created
+// with selectors, in which case it only covers the selected entities.
+type DeletePlan struct {
+	Path          string
+	DeleteUsers   bool
+	DeleteRoles   bool
+	EmptyProfiles bool `json:",omitempty"`
+	Filtered      bool `json:",omitempty"`
+
+	Users []*UserDeletion `json:",omitempty"`
+	Roles []*RoleDeletion `json:",omitempty"`
+}
+
+// UserDeletion lists the resources removed when a user is deleted.
+type UserDeletion struct {
+	Name string
+	ID   string
+	ARN  string
+
+	Groups              []string `json:",omitempty"`
+	AttachedPolicies    []string `json:",omitempty"`
+	InlinePolicies      []string `json:",omitempty"`
+	AccessKeys          []string `json:",omitempty"`
+	LoginProfile        bool     `json:",omitempty"`
+	MFADevices          []string `json:",omitempty"`
+	SSHPublicKeys       []string `json:",omitempty"`
+	ServiceCredentials  []string `json:",omitempty"`
+	SigningCertificates []string `json:",omitempty"`
+	PermissionsBoundary string   `json:",omitempty"`
+}
+
+// RoleDeletion lists the resources removed when a role is deleted. The role is
+// removed from InstanceProfiles, and DeletedProfiles are deleted.
+type RoleDeletion struct {
+	Name string
+	ID   string
+	ARN  string
+
+	AttachedPolicies    []string `json:",omitempty"`
+	InlinePolicies      []string `json:",omitempty"`
+	InstanceProfiles    []string `json:",omitempty"`
+	DeletedProfiles     []string `json:",omitempty"`
+	PermissionsBoundary string   `json:",omitempty"`
+}
+
+// DriftError is returned by ApplyDeletePlan when the live IAM state no longer
+// matches the plan.
+type DriftError struct {
+	Path    string
+	Changes []string
+}
+
+// Error implements error interface.
+func (e *DriftError) Error() string {
+	return "iamx: state under " + e.Path + " has drifted from the plan (" +
+		strings.Join(e.Changes, ", ") + ")"
+}
+
+// PlanDeleteUsers returns a plan for deleting all users under the specified
+// IAM path without making any changes. It accepts the same options as
+// DeleteUsers.
+func (c Client) PlanDeleteUsers(path string,
+	opts ...DeleteOption) (*DeletePlan, error) {
+	o := getDeleteOpts(opts)
+	p := &DeletePlan{Path: path, DeleteUsers: true, Filtered: len(o.sel) > 0}
+	return p, c.plan(p, o.sel, nil)
+}
+
+// PlanDeleteRoles returns a plan for deleting all roles under the specified
+// IAM path without making any changes. It accepts the same options as
+// DeleteRoles.
+func (c Client) PlanDeleteRoles(path string,
+	opts ...DeleteOption) (*DeletePlan, error) {
+	o := getDeleteOpts(opts)
+	p := &DeletePlan{
+		Path:          path,
+		DeleteRoles:   true,
+		EmptyProfiles: o.emptyProfiles,
+		Filtered:      len(o.sel) > 0,
+	}
+	return p, c.plan(p, nil, o.sel)
+}
+
+// ApplyDeletePlan deletes the users and roles in a previously reviewed plan.
+// The plan is recomputed first, and nothing is deleted if any resource was
+// added, removed, or modified since the plan was created. In that case, the
+// returned error is a *DriftError. Entities added since the creation of a
+// filtered plan are ignored.
+func (c Client) ApplyDeletePlan(p *DeletePlan) error {
+	live := &DeletePlan{
+		Path:          p.Path,
+		DeleteUsers:   p.DeleteUsers,
+		DeleteRoles:   p.DeleteRoles,
+		EmptyProfiles: p.EmptyProfiles,
+		Filtered:      p.Filtered,
+	}
+	var userSel, roleSel []Selector
+	if p.Filtered {
+		names := make([]string, 0, len(p.Users))
+		for _, u := range p.Users {
+			names = append(names, u.Name)
+		}
+		userSel = []Selector{nameIn(names)}
+		names = make([]string, 0, len(p.Roles))
+		for _, r := range p.Roles {
+			names = append(names, r.Name)
+		}
+		roleSel = []Selector{nameIn(names)}
+	}
+	if err := c.plan(live, userSel, roleSel); err != nil {
+		return err
+	}
+	if changes := p.drift(live); len(changes) > 0 {
+		return &DriftError{Path: p.Path, Changes: changes}
+	}
+	var opts []DeleteOption
+	if p.EmptyProfiles {
+		opts = append(opts, DeleteEmptyProfiles)
+	}
+	return fast.Call(
+		func() error {
+			return fast.ForEachIO(len(p.Users), func(i int) error {
+				return c.DeleteUser(p.Users[i].Name)
+			})
+		},
+		func() error {
+			return fast.ForEachIO(len(p.Roles), func(i int) error {
+				return c.DeleteRole(p.Roles[i].Name, opts...)
+			})
+		},
+	)
+}
+
+// plan populates the Users and Roles fields of p with entities matching the
+// specified selectors.
+func (c Client) plan(p *DeletePlan, userSel, roleSel []Selector) error {
+	err := fast.Call(
+		func() error {
+			if !p.DeleteUsers {
+				return nil
+			}
+			users, err := c.selectUsers(p.Path, userSel)
+			if err != nil {
+				return err
+			}
+			p.Users = make([]*UserDeletion, len(users))
+			return fast.ForEachIO(len(users), func(i int) (err error) {
+				p.Users[i], err = c.planUser(&users[i])
+				return
+			})
+		},
+		func() error {
+			if !p.DeleteRoles {
+				return nil
+			}
+			roles, err := c.selectRoles(p.Path, roleSel)
+			if err != nil {
+				return err
+			}
+			p.Roles = make([]*RoleDeletion, len(roles))
+			return fast.ForEachIO(len(roles), func(i int) (err error) {
+				p.Roles[i], err = c.planRole(&roles[i], p.EmptyProfiles)
+				return
+			})
+		},
+	)
+	if err == nil {
+		sort.Slice(p.Users, func(i, j int) bool {
+			return p.Users[i].Name < p.Users[j].Name
+		})
+		sort.Slice(p.Roles, func(i, j int) bool {
+			return p.Roles[i].Name < p.Roles[j].Name
+		})
+	}
+	return err
+}
+
+// planUser returns all resources that would be removed by deleting user u.
+func (c Client) planUser(u *iam.User) (*UserDeletion, error) {
+	name := aws.StringValue(u.UserName)
+	d := &UserDeletion{
+		Name: name,
+		ID:   aws.StringValue(u.UserId),
+		ARN:  aws.StringValue(u.Arn),
+	}
+	err := fast.Call(
+		func() (err error) {
+			d.Groups, err = c.userGroups(name)
+			return
+		},
+		func() (err error) {
+			d.AttachedPolicies, err = c.attachedUserPolicies(name)
+			return
+		},
+		func() (err error) {
+			d.InlinePolicies, err = c.userPolicyNames(name)
+			return
+		},
+		func() (err error) {
+			d.AccessKeys, err = c.accessKeyIDs(name)
+			return
+		},
+		func() (err error) {
+			d.LoginProfile, err = c.hasLoginProfile(name)
+			return
+		},
+		func() (err error) {
+			d.MFADevices, err = c.mfaSerials(name)
+			return
+		},
+		func() (err error) {
+			d.SSHPublicKeys, err = c.sshPublicKeyIDs(name)
+			return
+		},
+		func() (err error) {
+			d.ServiceCredentials, err = c.serviceSpecificCredentialIDs(name)
+			return
+		},
+		func() (err error) {
+			d.SigningCertificates, err = c.signingCertificateIDs(name)
+			return
+		},
+		func() error {
+			in := iam.GetUserInput{UserName: aws.String(name)}
+			out, err := c.GetUserRequest(&in).Send()
+			if err == nil && out.User.PermissionsBoundary != nil {
+				d.PermissionsBoundary = aws.StringValue(
+					out.User.PermissionsBoundary.PermissionsBoundaryArn)
+			}
+			return err
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	for _, v := range []*[]string{&d.Groups, &d.AttachedPolicies,
+		&d.InlinePolicies, &d.AccessKeys, &d.MFADevices, &d.SSHPublicKeys,
+		&d.ServiceCredentials, &d.SigningCertificates} {
+		*v = toSet(*v)
+	}
+	return d, nil
+}
+
+// planRole returns all resources that would be removed by deleting role r.
+func (c Client) planRole(r *iam.Role, deleteEmpty bool) (*RoleDeletion,
+	error) {
+	name := aws.StringValue(r.RoleName)
+	d := &RoleDeletion{
+		Name: name,
+		ID:   aws.StringValue(r.RoleId),
+		ARN:  aws.StringValue(r.Arn),
+	}
+	err := fast.Call(
+		func() (err error) {
+			d.AttachedPolicies, err = c.attachedRolePolicies(name)
+			return
+		},
+		func() (err error) {
+			d.InlinePolicies, err = c.rolePolicyNames(name)
+			return
+		},
+		func() error {
+			ips, err := c.roleProfiles(name)
+			for i := range ips {
+				ip := aws.StringValue(ips[i].InstanceProfileName)
+				d.InstanceProfiles = append(d.InstanceProfiles, ip)
+				if deleteEmpty && onlyRole(&ips[i], name) {
+					d.DeletedProfiles = append(d.DeletedProfiles, ip)
+				}
+			}
+			return err
+		},
+		func() error {
+			in := iam.GetRoleInput{RoleName: aws.String(name)}
+			out, err := c.GetRoleRequest(&in).Send()
+			if err == nil && out.Role.PermissionsBoundary != nil {
+				d.PermissionsBoundary = aws.StringValue(
+					out.Role.PermissionsBoundary.PermissionsBoundaryArn)
+			}
+			return err
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	for _, v := range []*[]string{&d.AttachedPolicies, &d.InlinePolicies,
+		&d.InstanceProfiles, &d.DeletedProfiles} {
+		*v = toSet(*v)
+	}
+	return d, nil
+}
+
+// drift returns a description of all differences between plan p and the live
+// plan. Users and roles are matched by name.
+func (p *DeletePlan) drift(live *DeletePlan) []string {
+	var changes []string
+	if p.Path != live.Path || p.DeleteUsers != live.DeleteUsers ||
+		p.DeleteRoles != live.DeleteRoles ||
+		p.EmptyProfiles != live.EmptyProfiles || p.Filtered != live.Filtered {
+		changes = append(changes, "plan scope changed")
+	}
+	users := make(map[string]*UserDeletion, len(live.Users))
+	for _, u := range live.Users {
+		users[u.Name] = u
+	}
+	for _, u := range p.Users {
+		if cur, ok := users[u.Name]; !ok {
+			changes = append(changes, "user "+u.Name+" removed")
+		} else if !reflect.DeepEqual(u, cur) {
+			changes = append(changes, "user "+u.Name+" modified")
+		}
+		delete(users, u.Name)
+	}
+	for name := range users {
+		changes = append(changes, "user "+name+" added")
+	}
+	roles := make(map[string]*RoleDeletion, len(live.Roles))
+	for _, r := range live.Roles {
+		roles[r.Name] = r
+	}
+	for _, r := range p.Roles {
+		if cur, ok := roles[r.Name]; !ok {
+			changes = append(changes, "role "+r.Name+" removed")
+		} else if !reflect.DeepEqual(r, cur) {
+			changes = append(changes, "role "+r.Name+" modified")
+		}
+		delete(roles, r.Name)
+	}
+	for name := range roles {
+		changes = append(changes, "role "+name+" added")
+	}
+	sort.Strings(changes)
+	return changes
+}
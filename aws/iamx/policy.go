@@ -88,21 +88,36 @@ func ParsePolicy(s *string) (*Policy, error) {
 	return p, err
 }
 
-// Doc returns JSON representation of policy p.
+// Doc returns JSON representation of policy p. The version defaults to
+// PolicyVersion2012 if it is not set. Policy p is not modified, so Doc may be
+// called concurrently.
 func (p *Policy) Doc() *string {
-	if p.Version == "" {
-		p.Version = PolicyVersion2012
+	c := *p
+	if c.Version == "" {
+		c.Version = PolicyVersion2012
 	}
 	// Builder used to avoid HTML escaping
 	var b strings.Builder
 	enc := json.NewEncoder(&b)
 	enc.SetEscapeHTML(false)
-	if err := enc.Encode(p); err != nil {
+	if err := enc.Encode(&c); err != nil {
 		panic("policy: encode error: " + err.Error())
 	}
 	return aws.String(strings.TrimSuffix(b.String(), "\n"))
 }
 
+// Clone returns a deep copy of policy p.
+func (p *Policy) Clone() *Policy {
+	c := *p
+	if p.Statement != nil {
+		c.Statement = make([]*Statement, len(p.Statement))
+		for i, s := range p.Statement {
+			c.Statement[i] = s.Clone()
+		}
+	}
+	return &c
+}
+
 // Statement is an IAM policy statement.
 type Statement struct {
 	SID          string         `json:"Sid,omitempty"`
@@ -116,6 +131,28 @@ type Statement struct {
 	Condition    ConditionMap   `json:",omitempty"`
 }
 
+// Clone returns a deep copy of statement s.
+func (s *Statement) Clone() *Statement {
+	c := *s
+	c.Principal = s.Principal.clone()
+	c.NotPrincipal = s.NotPrincipal.clone()
+	c.Action = s.Action.clone()
+	c.NotAction = s.NotAction.clone()
+	c.Resource = s.Resource.clone()
+	c.NotResource = s.NotResource.clone()
+	if s.Condition != nil {
+		c.Condition = make(ConditionMap, len(s.Condition))
+		for op, conds := range s.Condition {
+			m := make(Conditions, len(conds))
+			for k, v := range conds {
+				m[k] = v.clone()
+			}
+			c.Condition[op] = m
+		}
+	}
+	return &c
+}
+
 // Effect is the statement allow/deny effect.
 type Effect string
 
@@ -143,6 +180,18 @@ func NewAWSPrincipal(ids ...string) *Principal {
 	return &Principal{PrincipalMap: PrincipalMap{AWS: PolicyMultiVal(ids)}}
 }
 
+// clone returns a deep copy of principal p.
+func (p *Principal) clone() *Principal {
+	if p == nil {
+		return nil
+	}
+	c := *p
+	c.AWS = p.AWS.clone()
+	c.Federated = p.Federated.clone()
+	c.Service = p.Service.clone()
+	return &c
+}
+
 // MarshalJSON implements json.Marshaler interface.
 func (p *Principal) MarshalJSON() ([]byte, error) {
 	if p.Any {
@@ -188,6 +237,15 @@ func (v PolicyMultiVal) Equal(o PolicyMultiVal) bool {
 	return true
 }
 
+// clone returns a copy of v, preserving the distinction between nil and empty
+// values.
+func (v PolicyMultiVal) clone() PolicyMultiVal {
+	if v == nil {
+		return nil
+	}
+	return append(make(PolicyMultiVal, 0, len(v)), v...)
+}
+
 // MarshalJSON implements json.Marshaler interface.
 func (v PolicyMultiVal) MarshalJSON() ([]byte, error) {
 	if len(v) == 0 {
@@ -238,3 +238,28 @@ func TestPolicyMultiVal(t *testing.T) {
 	assert.False(t, PolicyMultiVal{}.Equal(PolicyMultiVal{""}))
 	assert.False(t, PolicyMultiVal{"x"}.Equal(PolicyMultiVal{"y"}))
 }
+
+func TestPolicyClone(t *testing.T) {
+	doc := `{
+		"Statement": [{
+			"Effect": "Allow",
+			"Principal": {"AWS": "a"},
+			"Action": "s3:*",
+			"Resource": [],
+			"Condition": {"Bool": {"aws:SecureTransport": "true"}}
+		}]
+	}`
+	p, err := ParsePolicy(&doc)
+	require.NoError(t, err)
+	c := p.Clone()
+	require.Equal(t, p, c)
+	c.Statement[0].Principal.AWS[0] = "b"
+	c.Statement[0].Action[0] = "ec2:*"
+	c.Statement[0].Condition["Bool"]["aws:SecureTransport"][0] = "false"
+	assert.Equal(t, "a", p.Statement[0].Principal.AWS[0])
+	assert.Equal(t, "s3:*", p.Statement[0].Action[0])
+	assert.Equal(t, "true", p.Statement[0].Condition["Bool"]["aws:SecureTransport"][0])
+	assert.NotNil(t, c.Statement[0].Resource)
+	assert.Nil(t, c.Statement[0].NotResource)
+	assert.Equal(t, &Policy{}, (&Policy{}).Clone())
+}
@@ -0,0 +1,147 @@
+package iamx
+
+import "github.com/mxk/go-cloud/aws/arn"
+
+// PrincipalKind classifies an AWS principal entry.
+type PrincipalKind string
+
+// AWS principal kinds.
+const (
+	UnknownPrincipal       = PrincipalKind("")
+	AnyPrincipal           = PrincipalKind("*")
+	AccountPrincipal       = PrincipalKind("account")
+	RolePrincipal          = PrincipalKind("role")
+	UserPrincipal          = PrincipalKind("user")
+	AssumedRolePrincipal   = PrincipalKind("assumed-role")
+	FederatedUserPrincipal = PrincipalKind("federated-user")
+	UniqueIDPrincipal      = PrincipalKind("unique-id")
+)
+
+// PrincipalInfo describes a single AWS principal entry. Account is empty if
+// the owning account cannot be determined. Entity is set only for unique IDs.
+type PrincipalInfo struct {
+	ID      string
+	Kind    PrincipalKind
+	Account string
+	Entity  Entity
+}
+
+// ClassifyPrincipal determines the kind and owning account of AWS principal
+// id, which may be "*", an account ID, an IAM or STS ARN, or a unique ID.
+func ClassifyPrincipal(id string) PrincipalInfo {
+	pi := PrincipalInfo{ID: id}
+	switch {
+	case id == "*":
+		pi.Kind = AnyPrincipal
+	case isAccountID(id):
+		pi.Kind, pi.Account = AccountPrincipal, id
+	case arn.ARN(id).Valid():
+		r := arn.ARN(id)
+		if !isAccountID(r.Account()) {
+			break
+		}
+		switch svc, typ := r.Service(), r.Type(); {
+		case svc == "iam" && r.Resource() == "root":
+			pi.Kind = AccountPrincipal
+		case svc == "iam" && typ == "role":
+			pi.Kind = RolePrincipal
+		case svc == "iam" && typ == "user":
+			pi.Kind = UserPrincipal
+		case svc == "sts" && typ == "assumed-role":
+			pi.Kind = AssumedRolePrincipal
+		case svc == "sts" && typ == "federated-user":
+			pi.Kind = FederatedUserPrincipal
+		default:
+			return pi
+		}
+		pi.Account = r.Account()
+	default:
+		if e, err := ValidID(id); err == nil && e != Root && !e.Credential() {
+			pi.Kind, pi.Entity = UniqueIDPrincipal, e
+			pi.Account, _ = AccountID(id)
+		}
+	}
+	return pi
+}
+
+// Classify returns information about all AWS principals in p. A principal
+// wildcard is reported as "*".
+func (p *Principal) Classify() []PrincipalInfo {
+	if p == nil {
+		return nil
+	}
+	var all []PrincipalInfo
+	if p.Any {
+		all = append(all, ClassifyPrincipal("*"))
+	}
+	for _, id := range p.AWS {
+		all = append(all, ClassifyPrincipal(id))
+	}
+	return all
+}
+
+// NormalizeAccounts returns a copy of p with all bare account IDs in the AWS
+// element replaced by the root ARNs of the specified partition, which defaults
+// to aws. This matches the conversion performed by IAM when a policy is saved.
+func (p *Principal) NormalizeAccounts(partition string) *Principal {
+	c := p.clone()
+	if c == nil {
+		return nil
+	}
+	if partition == "" {
+		partition = "aws"
+	}
+	for i, id := range c.AWS {
+		if isAccountID(id) {
+			c.AWS[i] = string(arn.New(partition, "iam", "", id, "root"))
+		}
+	}
+	return c
+}
+
+// Accounts returns the sorted set of account IDs trusted by the AWS principals
+// in p. Principals with unknown accounts and wildcards are ignored.
+func (p *Principal) Accounts() []string {
+	var v []string
+	for _, pi := range p.Classify() {
+		if pi.Account != "" {
+			v = append(v, pi.Account)
+		}
+	}
+	return toSet(v)
+}
+
+// Public returns true if p allows any AWS principal.
+func (p *Principal) Public() bool {
+	for _, pi := range p.Classify() {
+		if pi.Kind == AnyPrincipal {
+			return true
+		}
+	}
+	return false
+}
+
+// CrossAccount returns true if p includes any AWS principals outside of the
+// specified account. Public principals and principals with unknown accounts
+// are treated as cross-account.
+func (p *Principal) CrossAccount(acct string) bool {
+	for _, pi := range p.Classify() {
+		if pi.Account != acct {
+			return true
+		}
+	}
+	return false
+}
+
+// isAccountID returns true if s is a 12-digit account ID.
+func isAccountID(s string) bool {
+	if len(s) != 12 {
+		return false
+	}
+	for i := range s {
+		if s[i] < '0' || '9' < s[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -1,37 +1,369 @@
 package iamx
 
 import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/iam"
+	"github.com/mxk/go-cloud/aws/arn"
+	"github.com/mxk/go-cloud/aws/awsx"
 	"github.com/mxk/go-fast"
 )
 
-// DeleteRoles deletes all roles under the specified IAM path.
-func (c Client) DeleteRoles(path string) error {
-	in := iam.ListRolesInput{PathPrefix: aws.String(path)}
-	r := c.ListRolesRequest(&in)
-	p := r.Paginate()
-	var roles []string
-	for p.Next() {
-		out := p.CurrentPage().Roles
-		for i := range out {
-			roles = append(roles, aws.StringValue(out[i].RoleName))
+// RoleSpec is the desired state of an IAM role. Path defaults to "/", is
+// normalized to begin and end with "/", and cannot be changed once the role is
+// created. Inline policies are keyed by
+// name. MaxSessionDuration is in seconds, with 0 meaning the IAM default of
+// one hour.
+type RoleSpec struct {
+	Path                string
+	AssumeRolePolicy    *Policy
+	Policies            map[string]*Policy
+	AttachedPolicies    []arn.ARN
+	PermissionsBoundary arn.ARN
+	Tags                map[string]string
+	MaxSessionDuration  int64
+}
+
+// path returns the role path in the "/.../" form used by IAM.
+func (s *RoleSpec) path() string {
+	if p := strings.Trim(s.Path, "/"); p != "" {
+		return "/" + p + "/"
+	}
+	return "/"
+}
+
+// maxSession returns the normalized maximum session duration.
+func (s *RoleSpec) maxSession() int64 {
+	if s.MaxSessionDuration == 0 {
+		return 3600
+	}
+	return s.MaxSessionDuration
+}
+
+// EnsureRole creates the specified role or updates an existing one to match
+// spec, returning the role ARN. Inline policies, attached policies, and tags
+// that are not in the spec are removed. Policies are compared in their
+// canonical form, so existing documents are only replaced when the
+// permissions they grant are different. Calling EnsureRole again after a
+// failure resumes where the previous call left off.
+func (c Client) EnsureRole(name string, spec *RoleSpec) (arn.ARN, error) {
+	if spec.AssumeRolePolicy == nil {
+		return "", errors.New("iamx: missing assume role policy")
+	}
+	for name, p := range spec.Policies {
+		if p == nil {
+			return "", fmt.Errorf("iamx: nil inline policy %q", name)
 		}
 	}
-	if err := p.Err(); err != nil {
+	in := iam.GetRoleInput{RoleName: aws.String(name)}
+	out, err := c.GetRoleRequest(&in).Send()
+	if err != nil {
+		if awsx.ErrCode(err) != iam.ErrCodeNoSuchEntityException {
+			return "", err
+		}
+		return c.createRole(name, spec)
+	}
+	role := out.Role
+	r := arn.Value(role.Arn)
+	if path := aws.StringValue(role.Path); path != spec.path() {
+		return r, fmt.Errorf("iamx: role %q has path %q instead of %q", name,
+			path, spec.path())
+	}
+	err = fast.Call(
+		func() error { return c.updateRoleTrust(role, spec.AssumeRolePolicy) },
+		func() error { return c.updateRoleSession(role, spec.maxSession()) },
+		func() error {
+			return c.updateRoleBoundary(role, spec.PermissionsBoundary)
+		},
+		func() error { return c.updateRoleTags(name, spec.Tags) },
+		func() error {
+			return c.updateRolePolicies(name, r.Partition(), spec.Policies)
+		},
+		func() error {
+			return c.updateAttachedRolePolicies(name, spec.AttachedPolicies)
+		},
+	)
+	return r, err
+}
+
+// createRole creates a new role from spec.
+func (c Client) createRole(name string, spec *RoleSpec) (arn.ARN, error) {
+	in := iam.CreateRoleInput{
+		AssumeRolePolicyDocument: spec.AssumeRolePolicy.Doc(),
+		Path:                     aws.String(spec.path()),
+		RoleName:                 aws.String(name),
+		Tags:                     makeTags(spec.Tags),
+	}
+	if spec.MaxSessionDuration != 0 {
+		in.MaxSessionDuration = aws.Int64(spec.MaxSessionDuration)
+	}
+	if spec.PermissionsBoundary != "" {
+		in.PermissionsBoundary = arn.String(spec.PermissionsBoundary)
+	}
+	out, err := c.CreateRoleRequest(&in).Send()
+	if err != nil {
+		return "", err
+	}
+	r := arn.Value(out.Role.Arn)
+	names := policyNames(spec.Policies)
+	return r, fast.Call(
+		func() error {
+			return fast.ForEachIO(len(names), func(i int) error {
+				return c.putRolePolicy(name, names[i], spec.Policies[names[i]])
+			})
+		},
+		func() error {
+			return fast.ForEachIO(len(spec.AttachedPolicies), func(i int) error {
+				return c.attachRolePolicy(name, spec.AttachedPolicies[i])
+			})
+		},
+	)
+}
+
+// updateRoleTrust updates the assume role policy if it grants different
+// permissions than p.
+func (c Client) updateRoleTrust(role *iam.Role, p *Policy) error {
+	cur, err := ParsePolicy(role.AssumeRolePolicyDocument)
+	if err == nil && samePolicy(cur, p, arn.Value(role.Arn).Partition()) {
+		return nil
+	}
+	in := iam.UpdateAssumeRolePolicyInput{
+		PolicyDocument: p.Doc(),
+		RoleName:       role.RoleName,
+	}
+	_, err = c.UpdateAssumeRolePolicyRequest(&in).Send()
+	return err
+}
+
+// updateRoleSession updates the maximum session duration of the role.
+func (c Client) updateRoleSession(role *iam.Role, d int64) error {
+	if aws.Int64Value(role.MaxSessionDuration) == d {
+		return nil
+	}
+	in := iam.UpdateRoleInput{
+		MaxSessionDuration: aws.Int64(d),
+		RoleName:           role.RoleName,
+	}
+	_, err := c.UpdateRoleRequest(&in).Send()
+	return err
+}
+
+// updateRoleBoundary sets or removes the role permissions boundary.
+func (c Client) updateRoleBoundary(role *iam.Role, b arn.ARN) error {
+	var cur arn.ARN
+	if role.PermissionsBoundary != nil {
+		cur = arn.Value(role.PermissionsBoundary.PermissionsBoundaryArn)
+	}
+	if cur == b {
+		return nil
+	}
+	if b == "" {
+		return c.deleteRoleBoundary(aws.StringValue(role.RoleName))
+	}
+	in := iam.PutRolePermissionsBoundaryInput{
+		PermissionsBoundary: arn.String(b),
+		RoleName:            role.RoleName,
+	}
+	_, err := c.PutRolePermissionsBoundaryRequest(&in).Send()
+	return err
+}
+
+// deleteRoleBoundary removes the role permissions boundary.
+func (c Client) deleteRoleBoundary(role string) error {
+	in := iam.DeleteRolePermissionsBoundaryInput{RoleName: aws.String(role)}
+	_, err := c.DeleteRolePermissionsBoundaryRequest(&in).Send()
+	return err
+}
+
+// updateRoleTags adds, updates, and removes role tags to match tags.
+func (c Client) updateRoleTags(role string, tags map[string]string) error {
+	cur, err := c.roleTags(role)
+	if err != nil {
+		return err
+	}
+	set, unset := diffTags(cur, tags)
+	return fast.Call(
+		func() error {
+			if len(set) == 0 {
+				return nil
+			}
+			in := iam.TagRoleInput{RoleName: aws.String(role), Tags: set}
+			_, err := c.TagRoleRequest(&in).Send()
+			return err
+		},
+		func() error {
+			if len(unset) == 0 {
+				return nil
+			}
+			in := iam.UntagRoleInput{
+				RoleName: aws.String(role),
+				TagKeys:  unset,
+			}
+			_, err := c.UntagRoleRequest(&in).Send()
+			return err
+		},
+	)
+}
+
+// roleTags returns all role tags.
+func (c Client) roleTags(role string) (map[string]string, error) {
+	in := iam.ListRoleTagsInput{RoleName: aws.String(role)}
+	tags := make(map[string]string)
+	for {
+		out, err := c.ListRoleTagsRequest(&in).Send()
+		if err != nil {
+			return nil, err
+		}
+		for _, t := range out.Tags {
+			tags[aws.StringValue(t.Key)] = aws.StringValue(t.Value)
+		}
+		if !aws.BoolValue(out.IsTruncated) {
+			return tags, nil
+		}
+		in.Marker = out.Marker
+	}
+}
+
+// updateRolePolicies puts and deletes inline role policies to match pols.
+func (c Client) updateRolePolicies(role, partition string,
+	pols map[string]*Policy) error {
+	cur, err := c.rolePolicyNames(role)
+	if err != nil {
+		return err
+	}
+	cur = toSet(cur)
+	var del []string
+	for _, name := range cur {
+		if pols[name] == nil {
+			del = append(del, name)
+		}
+	}
+	names := policyNames(pols)
+	return fast.Call(
+		func() error {
+			return fast.ForEachIO(len(names), func(i int) error {
+				name, p := names[i], pols[names[i]]
+				if contains(cur, name) {
+					in := iam.GetRolePolicyInput{
+						PolicyName: aws.String(name),
+						RoleName:   aws.String(role),
+					}
+					out, err := c.GetRolePolicyRequest(&in).Send()
+					if err != nil {
+						return err
+					}
+					old, err := ParsePolicy(out.PolicyDocument)
+					if err == nil && samePolicy(old, p, partition) {
+						return nil
+					}
+				}
+				return c.putRolePolicy(role, name, p)
+			})
+		},
+		func() error {
+			return fast.ForEachIO(len(del), func(i int) error {
+				return c.deleteRolePolicy(role, del[i])
+			})
+		},
+	)
+}
+
+// putRolePolicy creates or replaces an inline role policy.
+func (c Client) putRolePolicy(role, name string, p *Policy) error {
+	in := iam.PutRolePolicyInput{
+		PolicyDocument: p.Doc(),
+		PolicyName:     aws.String(name),
+		RoleName:       aws.String(role),
+	}
+	_, err := c.PutRolePolicyRequest(&in).Send()
+	return err
+}
+
+// updateAttachedRolePolicies attaches and detaches managed role policies to
+// match arns.
+func (c Client) updateAttachedRolePolicies(role string, arns []arn.ARN) error {
+	cur, err := c.attachedRolePolicies(role)
+	if err != nil {
+		return err
+	}
+	want := make([]string, len(arns))
+	for i, r := range arns {
+		want[i] = string(r)
+	}
+	want = toSet(want)
+	d := diffSets(toSet(cur), want)
+	return fast.Call(
+		func() error {
+			return fast.ForEachIO(len(d.Added), func(i int) error {
+				return c.attachRolePolicy(role, arn.ARN(d.Added[i]))
+			})
+		},
+		func() error {
+			return fast.ForEachIO(len(d.Removed), func(i int) error {
+				return c.detachRolePolicy(role, d.Removed[i])
+			})
+		},
+	)
+}
+
+// attachRolePolicy attaches a managed policy to the role.
+func (c Client) attachRolePolicy(role string, policy arn.ARN) error {
+	in := iam.AttachRolePolicyInput{
+		PolicyArn: arn.String(policy),
+		RoleName:  aws.String(role),
+	}
+	_, err := c.AttachRolePolicyRequest(&in).Send()
+	return err
+}
+
+// DeleteRoles deletes all roles under the specified IAM path. If any selectors
+// are specified, only the roles matching all of them are deleted.
+func (c Client) DeleteRoles(path string, opts ...DeleteOption) error {
+	roles, err := c.selectRoles(path, getDeleteOpts(opts).sel)
+	if err != nil {
 		return err
 	}
 	return fast.ForEachIO(len(roles), func(i int) error {
-		return c.DeleteRole(roles[i])
+		return c.DeleteRole(aws.StringValue(roles[i].RoleName), opts...)
 	})
 }
 
+// listRoles returns all roles under the specified IAM path.
+func (c Client) listRoles(path string) ([]iam.Role, error) {
+	in := iam.ListRolesInput{PathPrefix: aws.String(path)}
+	r := c.ListRolesRequest(&in)
+	p := r.Paginate()
+	var roles []iam.Role
+	for p.Next() {
+		roles = append(roles, p.CurrentPage().Roles...)
+	}
+	return roles, p.Err()
+}
+
 // DeleteRole deletes the specified role, ensuring that all prerequisites for
-// deletion are met.
-func (c Client) DeleteRole(role string) error {
+// deletion are met. The role is removed from all instance profiles, which are
+// also deleted if they become empty and DeleteEmptyProfiles is specified.
+// Selectors are ignored.
+func (c Client) DeleteRole(role string, opts ...DeleteOption) error {
 	err := fast.Call(
 		func() error { return c.detachRolePolicies(role) },
 		func() error { return c.deleteRolePolicies(role) },
+		func() error {
+			return c.removeRoleFromProfiles(role,
+				getDeleteOpts(opts).emptyProfiles)
+		},
+		func() error {
+			in := iam.GetRoleInput{RoleName: aws.String(role)}
+			out, err := c.GetRoleRequest(&in).Send()
+			if err != nil || out.Role.PermissionsBoundary == nil {
+				return err
+			}
+			return ignoreNoSuchEntity(c.deleteRoleBoundary(role))
+		},
 	)
 	if err == nil {
 		in := iam.DeleteRoleInput{RoleName: aws.String(role)}
@@ -40,8 +372,113 @@ func (c Client) DeleteRole(role string) error {
 	return err
 }
 
+// DeleteInstanceProfiles deletes all instance profiles under the specified IAM
+// path. Roles are removed from the profiles, but are not deleted.
+func (c Client) DeleteInstanceProfiles(path string) error {
+	in := iam.ListInstanceProfilesInput{PathPrefix: aws.String(path)}
+	r := c.ListInstanceProfilesRequest(&in)
+	p := r.Paginate()
+	var ips []iam.InstanceProfile
+	for p.Next() {
+		ips = append(ips, p.CurrentPage().InstanceProfiles...)
+	}
+	if err := p.Err(); err != nil {
+		return err
+	}
+	return fast.ForEachIO(len(ips), func(i int) error {
+		return c.deleteInstanceProfile(&ips[i])
+	})
+}
+
+// deleteInstanceProfile removes all roles from the instance profile and
+// deletes it.
+func (c Client) deleteInstanceProfile(ip *iam.InstanceProfile) error {
+	name := aws.StringValue(ip.InstanceProfileName)
+	err := fast.ForEachIO(len(ip.Roles), func(i int) error {
+		return c.removeRoleFromProfile(aws.StringValue(ip.Roles[i].RoleName),
+			name)
+	})
+	if err == nil {
+		in := iam.DeleteInstanceProfileInput{
+			InstanceProfileName: aws.String(name),
+		}
+		_, err = c.DeleteInstanceProfileRequest(&in).Send()
+	}
+	return err
+}
+
+// removeRoleFromProfiles removes the role from all instance profiles. Profiles
+// that do not contain any other roles are deleted if deleteEmpty is true.
+func (c Client) removeRoleFromProfiles(role string, deleteEmpty bool) error {
+	ips, err := c.roleProfiles(role)
+	if err != nil {
+		return err
+	}
+	return fast.ForEachIO(len(ips), func(i int) error {
+		ip := &ips[i]
+		if deleteEmpty && onlyRole(ip, role) {
+			return c.deleteInstanceProfile(ip)
+		}
+		return c.removeRoleFromProfile(role,
+			aws.StringValue(ip.InstanceProfileName))
+	})
+}
+
+// roleProfiles returns all instance profiles containing the role.
+func (c Client) roleProfiles(role string) ([]iam.InstanceProfile, error) {
+	in := iam.ListInstanceProfilesForRoleInput{RoleName: aws.String(role)}
+	r := c.ListInstanceProfilesForRoleRequest(&in)
+	p := r.Paginate()
+	var ips []iam.InstanceProfile
+	for p.Next() {
+		ips = append(ips, p.CurrentPage().InstanceProfiles...)
+	}
+	return ips, p.Err()
+}
+
+// removeRoleFromProfile removes the role from an instance profile.
+func (c Client) removeRoleFromProfile(role, profile string) error {
+	in := iam.RemoveRoleFromInstanceProfileInput{
+		InstanceProfileName: aws.String(profile),
+		RoleName:            aws.String(role),
+	}
+	_, err := c.RemoveRoleFromInstanceProfileRequest(&in).Send()
+	return err
+}
+
+// onlyRole returns true if role is the only role in the instance profile.
+func onlyRole(ip *iam.InstanceProfile, role string) bool {
+	for i := range ip.Roles {
+		if aws.StringValue(ip.Roles[i].RoleName) != role {
+			return false
+		}
+	}
+	return true
+}
+
 // detachRolePolicies detaches all role policies.
 func (c Client) detachRolePolicies(role string) error {
+	arns, err := c.attachedRolePolicies(role)
+	if err != nil {
+		return err
+	}
+	return fast.ForEachIO(len(arns), func(i int) error {
+		return c.detachRolePolicy(role, arns[i])
+	})
+}
+
+// detachRolePolicy detaches a managed policy from the role.
+func (c Client) detachRolePolicy(role, policy string) error {
+	in := iam.DetachRolePolicyInput{
+		PolicyArn: aws.String(policy),
+		RoleName:  aws.String(role),
+	}
+	_, err := c.DetachRolePolicyRequest(&in).Send()
+	return err
+}
+
+// attachedRolePolicies returns the ARNs of all managed role policies.
+func (c Client) attachedRolePolicies(role string) ([]string, error) {
 	in := iam.ListAttachedRolePoliciesInput{RoleName: aws.String(role)}
 	r := c.ListAttachedRolePoliciesRequest(&in)
 	p := r.Paginate()
@@ -52,21 +489,32 @@ func (c Client) detachRolePolicies(role string) error {
 			arns = append(arns, aws.StringValue(out[i].PolicyArn))
 		}
 	}
-	if err := p.Err(); err != nil {
+	return arns, p.Err()
+}
+
+// deleteRolePolicies deletes all inline role policies.
+func (c Client) deleteRolePolicies(role string) error {
+	names, err := c.rolePolicyNames(role)
+	if err != nil {
 		return err
 	}
-	return fast.ForEachIO(len(arns), func(i int) error {
-		in := iam.DetachRolePolicyInput{
-			PolicyArn: aws.String(arns[i]),
-			RoleName:  aws.String(role),
-		}
-		_, err := c.DetachRolePolicyRequest(&in).Send()
-		return err
+	return fast.ForEachIO(len(names), func(i int) error {
+		return c.deleteRolePolicy(role, names[i])
 	})
 }
 
-// deleteRolePolicies deletes all inline role policies.
-func (c Client) deleteRolePolicies(role string) error {
+// deleteRolePolicy deletes an inline role policy.
+func (c Client) deleteRolePolicy(role, name string) error {
+	in := iam.DeleteRolePolicyInput{
+		PolicyName: aws.String(name),
+		RoleName:   aws.String(role),
+	}
+	_, err := c.DeleteRolePolicyRequest(&in).Send()
+	return err
+}
+
+// rolePolicyNames returns the names of all inline role policies.
+func (c Client) rolePolicyNames(role string) ([]string, error) {
 	in := iam.ListRolePoliciesInput{RoleName: aws.String(role)}
 	r := c.ListRolePoliciesRequest(&in)
 	p := r.Paginate()
@@ -74,15 +522,72 @@ func (c Client) deleteRolePolicies(role string) error {
 	for p.Next() {
 		names = append(names, p.CurrentPage().PolicyNames...)
 	}
-	if err := p.Err(); err != nil {
-		return err
+	return names, p.Err()
+}
+
+// samePolicy returns true if policies a and b grant the same permissions.
+// Bare account IDs are converted to root ARNs in the specified partition, as
+// IAM does when storing a policy.
+func samePolicy(a, b *Policy, partition string) bool {
+	return *accountsToARNs(a, partition).Canonical() ==
+		*accountsToARNs(b, partition).Canonical()
+}
+
+// accountsToARNs returns a copy of p with all bare account IDs in principals
+// converted to root ARNs.
+func accountsToARNs(p *Policy, partition string) *Policy {
+	c := p.Clone()
+	for _, s := range c.Statement {
+		s.Principal = s.Principal.NormalizeAccounts(partition)
+		s.NotPrincipal = s.NotPrincipal.NormalizeAccounts(partition)
 	}
-	return fast.ForEachIO(len(names), func(i int) error {
-		in := iam.DeleteRolePolicyInput{
-			PolicyName: aws.String(names[i]),
-			RoleName:   aws.String(role),
+	return c
+}
+
+// makeTags converts a tag map to a list of IAM tags sorted by key.
+func makeTags(m map[string]string) []iam.Tag {
+	if len(m) == 0 {
+		return nil
+	}
+	tags := make([]iam.Tag, 0, len(m))
+	for _, k := range tagKeys(m) {
+		tags = append(tags, iam.Tag{Key: aws.String(k), Value: aws.String(m[k])})
+	}
+	return tags
+}
+
+// diffTags returns tags that must be set and removed to change cur into want.
+func diffTags(cur, want map[string]string) (set []iam.Tag, unset []string) {
+	for _, k := range tagKeys(want) {
+		if v, ok := cur[k]; !ok || v != want[k] {
+			set = append(set, iam.Tag{Key: aws.String(k),
+				Value: aws.String(want[k])})
 		}
-		_, err := c.DeleteRolePolicyRequest(&in).Send()
-		return err
-	})
+	}
+	for _, k := range tagKeys(cur) {
+		if _, ok := want[k]; !ok {
+			unset = append(unset, k)
+		}
+	}
+	return
+}
+
+// tagKeys returns the sorted keys of tag map m.
+func tagKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// policyNames returns the sorted names of policies in m.
+func policyNames(m map[string]*Policy) []string {
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
 }
@@ -0,0 +1,178 @@
+package iamx
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+	"github.com/mxk/go-cloud/aws/arn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnsureRole(t *testing.T) {
+	m := newMockIAM()
+	c := m.client()
+	inline := &Policy{Statement: []*Statement{{
+		Effect:   Allow,
+		Action:   PolicyMultiVal{"s3:GetObject"},
+		Resource: PolicyMultiVal{"*"},
+	}}}
+	spec := &RoleSpec{
+		Path:             "/app/",
+		AssumeRolePolicy: AssumeRolePolicy(Allow, "123456789012"),
+		Policies:         map[string]*Policy{"a": inline, "b": inline},
+		AttachedPolicies: []arn.ARN{
+			"arn:aws:iam::aws:policy/ReadOnlyAccess",
+		},
+		PermissionsBoundary: "arn:aws:iam::123456789012:policy/boundary",
+		Tags:                map[string]string{"k1": "v1", "k2": "v2"},
+		MaxSessionDuration:  7200,
+	}
+
+	// Create
+	r, err := c.EnsureRole("role", spec)
+	require.NoError(t, err)
+	assert.Equal(t, arn.ARN("arn:aws:iam::123456789012:role/app/role"), r)
+	assert.Equal(t, []string{"AttachRolePolicy", "CreateRole",
+		"PutRolePolicy", "PutRolePolicy"}, m.mutations())
+	mr := m.roles["role"]
+	assert.Equal(t, int64(7200), aws.Int64Value(mr.role.MaxSessionDuration))
+	assert.Equal(t, spec.Tags, mr.tags)
+	assert.Len(t, mr.inline, 2)
+	assert.Equal(t, map[string]bool{
+		"arn:aws:iam::aws:policy/ReadOnlyAccess": true,
+	}, mr.attached)
+
+	// No changes; IAM converts account IDs to root ARNs
+	mr.role.AssumeRolePolicyDocument = AssumeRolePolicy(Allow,
+		"arn:aws:iam::123456789012:root").Doc()
+	p := inline.Clone()
+	p.Statement[0].Action = PolicyMultiVal{"S3:GetObject", "s3:GetObject"}
+	mr.inline["a"] = *p.Doc()
+	r, err = c.EnsureRole("role", spec)
+	require.NoError(t, err)
+	assert.Equal(t, arn.ARN("arn:aws:iam::123456789012:role/app/role"), r)
+	assert.Empty(t, m.mutations())
+
+	// Path is normalized
+	for _, path := range []string{"app", "/app", "app/"} {
+		spec.Path = path
+		_, err = c.EnsureRole("role", spec)
+		require.NoError(t, err, "%s", path)
+		assert.Empty(t, m.mutations(), "%s", path)
+	}
+	spec.Path = "/app/"
+
+	// Update everything
+	mr.inline["c"] = *inline.Doc()
+	mr.attached["arn:aws:iam::aws:policy/Other"] = true
+	spec.AssumeRolePolicy = AssumeRolePolicy(Allow, "210987654321")
+	spec.Policies = map[string]*Policy{"a": AssumeRolePolicy(Allow, "*")}
+	spec.AttachedPolicies = nil
+	spec.PermissionsBoundary = ""
+	spec.Tags = map[string]string{"k1": "x", "k3": "v3"}
+	spec.MaxSessionDuration = 0
+	_, err = c.EnsureRole("role", spec)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"DeleteRolePermissionsBoundary",
+		"DeleteRolePolicy",
+		"DeleteRolePolicy",
+		"DetachRolePolicy",
+		"DetachRolePolicy",
+		"PutRolePolicy",
+		"TagRole",
+		"UntagRole",
+		"UpdateAssumeRolePolicy",
+		"UpdateRole",
+	}, m.mutations())
+	assert.Equal(t, int64(3600), aws.Int64Value(mr.role.MaxSessionDuration))
+	assert.Nil(t, mr.role.PermissionsBoundary)
+	assert.Equal(t, spec.Tags, mr.tags)
+	assert.Len(t, mr.inline, 1)
+	assert.Contains(t, mr.inline, "a")
+	assert.Empty(t, mr.attached)
+	cur, err := ParsePolicy(mr.role.AssumeRolePolicyDocument)
+	require.NoError(t, err)
+	assert.Equal(t, spec.AssumeRolePolicy.Doc(), cur.Doc())
+
+	// Path cannot be changed
+	spec.Path = "/other/"
+	_, err = c.EnsureRole("role", spec)
+	assert.EqualError(t, err,
+		`iamx: role "role" has path "/app/" instead of "/other/"`)
+	assert.Empty(t, m.mutations())
+
+	// Trust policy is required
+	_, err = c.EnsureRole("role", &RoleSpec{})
+	assert.Error(t, err)
+
+	// Path is normalized on creation
+	_, err = c.EnsureRole("other", &RoleSpec{
+		Path:             "svc/x",
+		AssumeRolePolicy: spec.AssumeRolePolicy,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "/svc/x/", aws.StringValue(m.roles["other"].role.Path))
+	assert.Equal(t, []string{"CreateRole"}, m.mutations())
+
+	// Inline policies cannot be nil
+	spec.Path = "/app/"
+	spec.Policies["c"] = nil
+	_, err = c.EnsureRole("role", spec)
+	assert.EqualError(t, err, `iamx: nil inline policy "c"`)
+	assert.Empty(t, m.mutations())
+}
+
+func TestDeleteRole(t *testing.T) {
+	m := newMockIAM()
+	c := m.client()
+	trust := AssumeRolePolicy(Allow, "123456789012")
+	a := m.addRole("/app/", "a", trust)
+	a.inline["p"] = *trust.Doc()
+	a.attached["arn:aws:iam::aws:policy/ReadOnlyAccess"] = true
+	a.role.PermissionsBoundary = &iam.AttachedPermissionsBoundary{
+		PermissionsBoundaryArn: aws.String(
+			"arn:aws:iam::123456789012:policy/boundary"),
+	}
+	m.addRole("/app/", "b", trust)
+	m.addRole("/other/", "c", trust)
+	m.addProfile("/app/", "a", "a")
+	m.addProfile("/app/", "b", "b")
+	m.addProfile("/app/", "shared", "a", "c")
+
+	require.NoError(t, c.DeleteRole("a"))
+	assert.Equal(t, []string{
+		"DeleteRole",
+		"DeleteRolePermissionsBoundary",
+		"DeleteRolePolicy",
+		"DetachRolePolicy",
+		"RemoveRoleFromInstanceProfile",
+		"RemoveRoleFromInstanceProfile",
+	}, m.mutations())
+	assert.Nil(t, m.roles["a"])
+	assert.Empty(t, m.profiles["a"].Roles)
+	assert.Len(t, m.profiles["shared"].Roles, 1)
+
+	// Roles without a boundary do not attempt to delete one
+	require.NoError(t, c.DeleteRoles("/app/", DeleteEmptyProfiles))
+	assert.Equal(t, []string{
+		"DeleteInstanceProfile",
+		"DeleteRole",
+		"RemoveRoleFromInstanceProfile",
+	}, m.mutations())
+	assert.Nil(t, m.roles["b"])
+	assert.Nil(t, m.profiles["b"])
+	assert.NotNil(t, m.roles["c"])
+
+	require.NoError(t, c.DeleteInstanceProfiles("/app/"))
+	assert.Equal(t, []string{
+		"DeleteInstanceProfile",
+		"DeleteInstanceProfile",
+		"RemoveRoleFromInstanceProfile",
+	}, m.mutations())
+	assert.Empty(t, m.profiles)
+	assert.NotNil(t, m.roles["c"])
+	require.NoError(t, c.DeleteRole("c"))
+}
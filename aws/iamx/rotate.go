@@ -0,0 +1,228 @@
+package iamx
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+)
+
+// MaxAccessKeys is the maximum number of access keys that an IAM user can have.
+const MaxAccessKeys = 2
+
+// RotationStep identifies the next step of access key rotation.
+type RotationStep int
+
+// Access key rotation steps.
+const (
+	RotateCreate     RotationStep = iota // Create a new key
+	RotateDeliver                        // Deliver the new key
+	RotateVerify                         // Verify the new key
+	RotateDeactivate                     // Deactivate the old key
+	RotateDelete                         // Delete the old key after grace
+	RotateDone                           // Rotation is complete
+)
+
+var rotationSteps = [...]string{
+	RotateCreate:     "CREATE",
+	RotateDeliver:    "DELIVER",
+	RotateVerify:     "VERIFY",
+	RotateDeactivate: "DEACTIVATE",
+	RotateDelete:     "DELETE",
+	RotateDone:       "DONE",
+}
+
+// String implements fmt.Stringer.
+func (s RotationStep) String() string {
+	if 0 <= s && int(s) < len(rotationSteps) {
+		return rotationSteps[s]
+	}
+	return fmt.Sprintf("RotationStep(%d)", int(s))
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (s RotationStep) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (s *RotationStep) UnmarshalText(b []byte) error {
+	for i, v := range rotationSteps {
+		if v == string(b) {
+			*s = RotationStep(i)
+			return nil
+		}
+	}
+	return fmt.Errorf("iamx: invalid rotation step %q", b)
+}
+
+// KeySink receives a newly created access key, including its secret. The key
+// is deleted immediately if the sink returns an error.
+type KeySink func(key *iam.AccessKey) error
+
+// RotationSaver persists the state of access key rotation. It is called before
+// a new key is passed to KeySink and after every completed step. Rotation
+// stops if it returns an error.
+type RotationSaver func(r *KeyRotation) error
+
+// KeyVerifier returns nil once the new access key is confirmed to work.
+type KeyVerifier func(user, keyID string) error
+
+// KeyRotation is the resumable state of access key rotation for one user. It
+// is persisted via RotationSaver as rotation progresses. The old key is
+// deleted once Grace has elapsed since it was deactivated.
+type KeyRotation struct {
+	User        string
+	Grace       time.Duration
+	Step        RotationStep
+	OldKey      string `json:",omitempty"`
+	NewKey      string `json:",omitempty"`
+	Deactivated time.Time
+}
+
+// Done returns true if key rotation is complete.
+func (r *KeyRotation) Done() bool { return r.Step == RotateDone }
+
+// String implements fmt.Stringer.
+func (r *KeyRotation) String() string {
+	return fmt.Sprintf("%s %s (old=%s new=%s)", r.User, r.Step, r.OldKey,
+		r.NewKey)
+}
+
+// RotateAccessKey performs all remaining steps of access key rotation, calling
+// save after each completed step. It creates a new key, deleting the oldest
+// inactive key first if the user already has MaxAccessKeys keys, and passes it
+// to sink. It then waits for verify to confirm that the new key works,
+// deactivates the old key, and deletes it after the grace period. It returns
+// nil without completing the rotation if the grace period has not yet elapsed.
+// If an error is returned, r identifies the step that failed, and calling
+// RotateAccessKey again with the last saved state resumes from that step. A new
+// key is saved with the RotateDeliver step before it is passed to sink, and
+// with the RotateVerify step once sink succeeds. Resuming from RotateDeliver
+// returns an error without deleting the key, because it may have been
+// delivered. The caller must then set Step to RotateVerify if the key was
+// received, or to RotateCreate to delete and replace it.
+func (c Client) RotateAccessKey(r *KeyRotation, save RotationSaver,
+	sink KeySink, verify KeyVerifier) error {
+	for {
+		var err error
+		switch r.Step {
+		case RotateCreate:
+			err = c.createRotationKey(r, save, sink)
+		case RotateDeliver:
+			return fmt.Errorf("iamx: delivery of access key %s for user %q "+
+				"is unconfirmed", r.NewKey, r.User)
+		case RotateVerify:
+			err = verify(r.User, r.NewKey)
+		case RotateDeactivate:
+			if r.OldKey != "" {
+				in := iam.UpdateAccessKeyInput{
+					AccessKeyId: aws.String(r.OldKey),
+					Status:      iam.StatusTypeInactive,
+					UserName:    aws.String(r.User),
+				}
+				_, err = c.UpdateAccessKeyRequest(&in).Send()
+				err = ignoreNoSuchEntity(err)
+			}
+			if err == nil {
+				r.Deactivated = time.Now()
+			}
+		case RotateDelete:
+			if time.Since(r.Deactivated) < r.Grace {
+				return nil
+			}
+			if r.OldKey != "" {
+				err = ignoreNoSuchEntity(c.deleteAccessKey(r.User, r.OldKey))
+			}
+		case RotateDone:
+			return nil
+		default:
+			return fmt.Errorf("iamx: invalid rotation step %d", int(r.Step))
+		}
+		if err != nil {
+			return err
+		}
+		r.Step++
+		if err = save(r); err != nil {
+			return err
+		}
+	}
+}
+
+// createRotationKey creates a new access key, saves its ID with the
+// RotateDeliver step, and passes it to sink. A key that is known not to have
+// been delivered by a previous attempt is deleted first. The new key is deleted
+// immediately if it cannot be saved or delivered, and the RotateCreate step is
+// saved again, so that resuming rotation does not treat the key as possibly
+// delivered.
+func (c Client) createRotationKey(r *KeyRotation, save RotationSaver,
+	sink KeySink) error {
+	if r.NewKey != "" {
+		err := ignoreNoSuchEntity(c.deleteAccessKey(r.User, r.NewKey))
+		if err != nil {
+			return err
+		}
+		r.NewKey = ""
+	}
+	keys, err := c.accessKeys(r.User)
+	if err != nil {
+		return err
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return aws.TimeValue(keys[i].CreateDate).Before(
+			aws.TimeValue(keys[j].CreateDate))
+	})
+	var active, inactive []string
+	for i := range keys {
+		id := aws.StringValue(keys[i].AccessKeyId)
+		if keys[i].Status == iam.StatusTypeActive {
+			active = append(active, id)
+		} else {
+			inactive = append(inactive, id)
+		}
+	}
+	if r.OldKey == "" && len(active) > 0 {
+		if len(active) > 1 {
+			return fmt.Errorf("iamx: user %q has %d active access keys",
+				r.User, len(active))
+		}
+		r.OldKey = active[0]
+	}
+	if len(keys) >= MaxAccessKeys {
+		if len(inactive) == 0 {
+			return fmt.Errorf("iamx: user %q has no inactive access keys "+
+				"to delete", r.User)
+		}
+		if err = c.deleteAccessKey(r.User, inactive[0]); err != nil {
+			return err
+		}
+	}
+	in := iam.CreateAccessKeyInput{UserName: aws.String(r.User)}
+	out, err := c.CreateAccessKeyRequest(&in).Send()
+	if err != nil {
+		return err
+	}
+	r.NewKey = aws.StringValue(out.AccessKey.AccessKeyId)
+	r.Step = RotateDeliver
+	saved := save(r)
+	if err = saved; err == nil {
+		if err = sink(out.AccessKey); err == nil {
+			return nil
+		}
+	}
+	r.Step = RotateCreate
+	if derr := c.deleteAccessKey(r.User, r.NewKey); derr != nil {
+		err = fmt.Errorf("iamx: failed to delete undelivered access key "+
+			"%s (%v) after error: %v", r.NewKey, derr, err)
+	} else {
+		r.NewKey = ""
+	}
+	if saved == nil {
+		// If this fails, resuming from RotateDeliver keeps the key, which is
+		// safe, but requires the caller to resolve the state.
+		_ = save(r)
+	}
+	return err
+}
@@ -0,0 +1,180 @@
+package iamx
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRotateAccessKey(t *testing.T) {
+	m := newMockIAM()
+	c := m.client()
+	u := m.addUser("/", "u")
+	now := time.Now()
+	u.keys = []iam.AccessKeyMetadata{{
+		AccessKeyId: aws.String("AKIAOLDINACTIVE00000"),
+		CreateDate:  aws.Time(now.Add(-2 * time.Hour)),
+		Status:      iam.StatusTypeInactive,
+	}, {
+		AccessKeyId: aws.String("AKIAOLDACTIVE0000000"),
+		CreateDate:  aws.Time(now.Add(-time.Hour)),
+		Status:      iam.StatusTypeActive,
+	}}
+	r := &KeyRotation{User: "u", Grace: time.Hour}
+	var saved []byte
+	var saveErr error
+	save := func(r *KeyRotation) error {
+		if saveErr != nil {
+			return saveErr
+		}
+		b, err := json.Marshal(r)
+		saved = b
+		return err
+	}
+	load := func() *KeyRotation {
+		var r KeyRotation
+		require.NoError(t, json.Unmarshal(saved, &r))
+		return &r
+	}
+	var got *iam.AccessKey
+	sinkErr := errors.New("sink failed")
+	sink := func(k *iam.AccessKey) error {
+		got = k
+		return sinkErr
+	}
+	verifyErr := errors.New("not verified")
+	verify := func(user, id string) error {
+		assert.Equal(t, "u", user)
+		assert.Equal(t, r.NewKey, id)
+		return verifyErr
+	}
+
+	// Sink failure deletes the new key immediately
+	assert.Equal(t, sinkErr, c.RotateAccessKey(r, save, sink, verify))
+	assert.Equal(t, RotateCreate, r.Step)
+	assert.Equal(t, "AKIAOLDACTIVE0000000", r.OldKey)
+	assert.Empty(t, r.NewKey)
+	assert.Equal(t, r, load())
+	assert.Equal(t, []string{"CreateAccessKey", "DeleteAccessKey",
+		"DeleteAccessKey"}, m.mutations())
+	require.Len(t, u.keys, 1)
+
+	// Save failure before delivery
+	saveErr = errors.New("save failed")
+	got = nil
+	assert.Equal(t, saveErr, c.RotateAccessKey(r, save, sink, verify))
+	assert.Nil(t, got)
+	assert.Empty(t, r.NewKey)
+	assert.Equal(t, []string{"CreateAccessKey", "DeleteAccessKey"},
+		m.mutations())
+	require.Len(t, u.keys, 1)
+
+	// Crash after delivery leaves the key in an unconfirmed state
+	sink = func(k *iam.AccessKey) error {
+		got, saveErr = k, errors.New("crash")
+		return nil
+	}
+	saveErr = nil
+	assert.EqualError(t, c.RotateAccessKey(r, save, sink, verify), "crash")
+	delivered := aws.StringValue(got.AccessKeyId)
+	r = load()
+	assert.Equal(t, RotateDeliver, r.Step)
+	assert.Equal(t, delivered, r.NewKey)
+	assert.Equal(t, []string{"CreateAccessKey"}, m.mutations())
+	saveErr = nil
+	sink = func(k *iam.AccessKey) error {
+		got = k
+		return nil
+	}
+
+	// Unconfirmed key is never deleted automatically
+	err := c.RotateAccessKey(r, save, sink, verify)
+	assert.EqualError(t, err, "iamx: delivery of access key "+delivered+
+		` for user "u" is unconfirmed`)
+	assert.Equal(t, RotateDeliver, r.Step)
+	assert.Empty(t, m.mutations())
+	require.Len(t, u.keys, 2)
+
+	// Caller decides to replace it
+	r.Step = RotateCreate
+
+	// Verification failure
+	assert.Equal(t, verifyErr, c.RotateAccessKey(r, save, sink, verify))
+	assert.Equal(t, RotateVerify, r.Step)
+	assert.NotEqual(t, delivered, r.NewKey)
+	assert.Equal(t, r.NewKey, aws.StringValue(got.AccessKeyId))
+	assert.Equal(t, []string{"CreateAccessKey", "DeleteAccessKey"},
+		m.mutations())
+	require.Len(t, u.keys, 2)
+
+	// Resume from persisted state
+	assert.Contains(t, string(saved), `"Step":"VERIFY"`)
+	assert.Equal(t, r, load())
+	r = load()
+
+	// Grace period
+	verifyErr = nil
+	require.NoError(t, c.RotateAccessKey(r, save, sink, verify))
+	assert.Equal(t, RotateDelete, r.Step)
+	assert.False(t, r.Done())
+	assert.Equal(t, RotateDelete, load().Step)
+	assert.True(t, r.Deactivated.Equal(load().Deactivated))
+	assert.Equal(t, []string{"UpdateAccessKey"}, m.mutations())
+	require.Len(t, u.keys, 2)
+	assert.Equal(t, iam.StatusTypeInactive, u.keys[0].Status)
+	require.NoError(t, c.RotateAccessKey(r, save, sink, verify))
+	assert.Empty(t, m.mutations())
+
+	// Done
+	r.Deactivated = r.Deactivated.Add(-r.Grace)
+	require.NoError(t, c.RotateAccessKey(r, save, sink, verify))
+	assert.True(t, r.Done())
+	assert.True(t, load().Done())
+	assert.Equal(t, []string{"DeleteAccessKey"}, m.mutations())
+	require.Len(t, u.keys, 1)
+	assert.Equal(t, r.NewKey, aws.StringValue(u.keys[0].AccessKeyId))
+	assert.Equal(t, "u DONE (old=AKIAOLDACTIVE0000000 new="+r.NewKey+")",
+		r.String())
+}
+
+func TestRotateAccessKeyLimit(t *testing.T) {
+	m := newMockIAM()
+	c := m.client()
+	u := m.addUser("/", "u")
+	u.keys = []iam.AccessKeyMetadata{{
+		AccessKeyId: aws.String("AKIAACTIVE1000000000"),
+		Status:      iam.StatusTypeActive,
+	}, {
+		AccessKeyId: aws.String("AKIAACTIVE2000000000"),
+		Status:      iam.StatusTypeActive,
+	}}
+	r := &KeyRotation{User: "u"}
+	save := func(*KeyRotation) error { return nil }
+	err := c.RotateAccessKey(r, save, nil, nil)
+	assert.EqualError(t, err, `iamx: user "u" has 2 active access keys`)
+	assert.Equal(t, RotateCreate, r.Step)
+	assert.Empty(t, m.mutations())
+
+	// No keys
+	u.keys = nil
+	var keys []string
+	sink := func(k *iam.AccessKey) error {
+		keys = append(keys, aws.StringValue(k.AccessKeyId))
+		return nil
+	}
+	verify := func(string, string) error { return nil }
+	require.NoError(t, c.RotateAccessKey(r, save, sink, verify))
+	assert.True(t, r.Done())
+	assert.Empty(t, r.OldKey)
+	assert.Equal(t, []string{r.NewKey}, keys)
+
+	var s RotationStep
+	assert.Error(t, s.UnmarshalText([]byte("BAD")))
+	assert.Equal(t, "RotationStep(9)", RotationStep(9).String())
+}
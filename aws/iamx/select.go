@@ -0,0 +1,334 @@
+package iamx
+
+import (
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+	"github.com/mxk/go-fast"
+)
+
+// DeleteOption modifies the behavior of delete operations. It is either
+// DeleteEmptyProfiles or a Selector.
+type DeleteOption interface {
+	applyDelete(o *deleteOpts)
+}
+
+// deleteFlag is a DeleteOption that does not carry any data.
+type deleteFlag int
+
+// Delete options.
+const (
+	// DeleteEmptyProfiles deletes instance profiles that no longer contain any
+	// roles after the role being deleted is removed from them.
+	DeleteEmptyProfiles deleteFlag = iota + 1
+)
+
+// applyDelete implements DeleteOption.
+func (f deleteFlag) applyDelete(o *deleteOpts) {
+	if f == DeleteEmptyProfiles {
+		o.emptyProfiles = true
+	}
+}
+
+// deleteOpts contains the combined effect of all DeleteOptions.
+type deleteOpts struct {
+	emptyProfiles bool
+	sel           []Selector
+}
+
+// getDeleteOpts combines all delete options.
+func getDeleteOpts(opts []DeleteOption) deleteOpts {
+	var o deleteOpts
+	for _, opt := range opts {
+		opt.applyDelete(&o)
+	}
+	return o
+}
+
+// Candidate describes a user or role being considered by a bulk operation.
+// Tags and LastUsed are only populated if required by a selector. LastUsed is
+// nil if the last use time is unknown and zero if the entity was never used.
+// IAM API does not report last use times for roles.
+type Candidate struct {
+	Name     string
+	Path     string
+	ARN      string
+	ID       string
+	Created  time.Time
+	LastUsed *time.Time
+	Tags     map[string]string
+}
+
+// candidateInfo identifies additional information that must be fetched for a
+// candidate before it can be matched by a selector.
+type candidateInfo uint8
+
+const (
+	needTags candidateInfo = 1 << iota
+	needLastUsed
+)
+
+// Selector restricts bulk operations to users and roles matching a condition.
+// Selectors can be passed to DeleteUsers, DeleteRoles, PlanDeleteUsers, and
+// PlanDeleteRoles. Multiple selectors must all match for an entity to be
+// selected. The zero value does not match anything.
+type Selector struct {
+	match func(c *Candidate) bool
+	need  candidateInfo
+}
+
+// applyDelete implements DeleteOption.
+func (s Selector) applyDelete(o *deleteOpts) { o.sel = append(o.sel, s) }
+
+// Match returns true if candidate c is selected by s.
+func (s Selector) Match(c *Candidate) bool {
+	return s.match != nil && s.match(c)
+}
+
+// HasTag selects entities that have the specified tag key.
+func HasTag(key string) Selector {
+	return Selector{func(c *Candidate) bool {
+		_, ok := c.Tags[key]
+		return ok
+	}, needTags}
+}
+
+// TagEquals selects entities that have the specified tag key and value.
+func TagEquals(key, val string) Selector {
+	return Selector{func(c *Candidate) bool {
+		v, ok := c.Tags[key]
+		return ok && v == val
+	}, needTags}
+}
+
+// NameMatches selects entities with names matching re.
+func NameMatches(re *regexp.Regexp) Selector {
+	return Selector{func(c *Candidate) bool {
+		return re.MatchString(c.Name)
+	}, 0}
+}
+
+// CreatedBefore selects entities created before t.
+func CreatedBefore(t time.Time) Selector {
+	return Selector{func(c *Candidate) bool {
+		return !c.Created.IsZero() && c.Created.Before(t)
+	}, 0}
+}
+
+// OlderThan selects entities created more than d ago.
+func OlderThan(d time.Duration) Selector {
+	return CreatedBefore(time.Now().Add(-d))
+}
+
+// UnusedSince selects entities that have not been used since t, including
+// those that were never used. Entities with unknown last use times, such as
+// roles, are never selected.
+func UnusedSince(t time.Time) Selector {
+	return Selector{func(c *Candidate) bool {
+		return c.LastUsed != nil && c.LastUsed.Before(t)
+	}, needLastUsed}
+}
+
+// ExpiredAt selects entities with an expiration timestamp tag that is at or
+// before t. The tag value must be in RFC 3339 format or a Unix time in
+// seconds. Entities with missing or invalid tags are never selected.
+func ExpiredAt(key string, t time.Time) Selector {
+	return Selector{func(c *Candidate) bool {
+		v, ok := c.Tags[key]
+		if !ok {
+			return false
+		}
+		exp, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			sec, err := strconv.ParseInt(v, 10, 64)
+			if err != nil {
+				return false
+			}
+			exp = time.Unix(sec, 0)
+		}
+		return !exp.After(t)
+	}, needTags}
+}
+
+// Expired selects entities with an expiration timestamp tag that is in the
+// past. See ExpiredAt for the tag format.
+func Expired(key string) Selector { return ExpiredAt(key, time.Now()) }
+
+// nameIn selects entities with the specified names.
+func nameIn(names []string) Selector {
+	set := make(map[string]bool, len(names))
+	for _, n := range names {
+		set[n] = true
+	}
+	return Selector{func(c *Candidate) bool { return set[c.Name] }, 0}
+}
+
+// selectAll returns true if candidate c matches all selectors.
+func selectAll(c *Candidate, sel []Selector) bool {
+	for _, s := range sel {
+		if !s.Match(c) {
+			return false
+		}
+	}
+	return true
+}
+
+// needs returns the additional information required by all selectors.
+func needs(sel []Selector) (need candidateInfo) {
+	for _, s := range sel {
+		need |= s.need
+	}
+	return
+}
+
+// selectUsers returns all users under the specified IAM path that match all
+// selectors.
+func (c Client) selectUsers(path string, sel []Selector) ([]iam.User, error) {
+	users, err := c.listUsers(path)
+	if err != nil || len(sel) == 0 {
+		return users, err
+	}
+	need := needs(sel)
+	match := make([]bool, len(users))
+	err = fast.ForEachIO(len(users), func(i int) error {
+		cd, err := c.userCandidate(&users[i], need)
+		if err == nil {
+			match[i] = selectAll(cd, sel)
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	keep := users[:0]
+	for i := range users {
+		if match[i] {
+			keep = append(keep, users[i])
+		}
+	}
+	return keep, nil
+}
+
+// selectRoles returns all roles under the specified IAM path that match all
+// selectors.
+func (c Client) selectRoles(path string, sel []Selector) ([]iam.Role, error) {
+	roles, err := c.listRoles(path)
+	if err != nil || len(sel) == 0 {
+		return roles, err
+	}
+	need := needs(sel)
+	match := make([]bool, len(roles))
+	err = fast.ForEachIO(len(roles), func(i int) error {
+		cd, err := c.roleCandidate(&roles[i], need)
+		if err == nil {
+			match[i] = selectAll(cd, sel)
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	keep := roles[:0]
+	for i := range roles {
+		if match[i] {
+			keep = append(keep, roles[i])
+		}
+	}
+	return keep, nil
+}
+
+// userCandidate returns a selection candidate for user u.
+func (c Client) userCandidate(u *iam.User, need candidateInfo) (*Candidate,
+	error) {
+	name := aws.StringValue(u.UserName)
+	cd := &Candidate{
+		Name:    name,
+		Path:    aws.StringValue(u.Path),
+		ARN:     aws.StringValue(u.Arn),
+		ID:      aws.StringValue(u.UserId),
+		Created: aws.TimeValue(u.CreateDate),
+	}
+	err := fast.Call(
+		func() (err error) {
+			if need&needTags != 0 {
+				cd.Tags, err = c.userTags(name)
+			}
+			return
+		},
+		func() error {
+			if need&needLastUsed == 0 {
+				return nil
+			}
+			t, err := c.userLastUsed(u)
+			cd.LastUsed = &t
+			return err
+		},
+	)
+	return cd, err
+}
+
+// roleCandidate returns a selection candidate for role r.
+func (c Client) roleCandidate(r *iam.Role, need candidateInfo) (*Candidate,
+	error) {
+	name := aws.StringValue(r.RoleName)
+	cd := &Candidate{
+		Name:    name,
+		Path:    aws.StringValue(r.Path),
+		ARN:     aws.StringValue(r.Arn),
+		ID:      aws.StringValue(r.RoleId),
+		Created: aws.TimeValue(r.CreateDate),
+	}
+	var err error
+	if need&needTags != 0 {
+		cd.Tags, err = c.roleTags(name)
+	}
+	return cd, err
+}
+
+// userTags returns all user tags.
+func (c Client) userTags(user string) (map[string]string, error) {
+	in := iam.ListUserTagsInput{UserName: aws.String(user)}
+	tags := make(map[string]string)
+	for {
+		out, err := c.ListUserTagsRequest(&in).Send()
+		if err != nil {
+			return nil, err
+		}
+		for _, t := range out.Tags {
+			tags[aws.StringValue(t.Key)] = aws.StringValue(t.Value)
+		}
+		if !aws.BoolValue(out.IsTruncated) {
+			return tags, nil
+		}
+		in.Marker = out.Marker
+	}
+}
+
+// userLastUsed returns the time when user u last signed in or used an access
+// key. It returns zero time if the user was never used.
+func (c Client) userLastUsed(u *iam.User) (time.Time, error) {
+	last := aws.TimeValue(u.PasswordLastUsed)
+	name := aws.StringValue(u.UserName)
+	ids, err := c.accessKeyIDs(name)
+	if err != nil {
+		return last, err
+	}
+	used := make([]time.Time, len(ids))
+	err = fast.ForEachIO(len(ids), func(i int) error {
+		in := iam.GetAccessKeyLastUsedInput{AccessKeyId: aws.String(ids[i])}
+		out, err := c.GetAccessKeyLastUsedRequest(&in).Send()
+		if err == nil && out.AccessKeyLastUsed != nil {
+			used[i] = aws.TimeValue(out.AccessKeyLastUsed.LastUsedDate)
+		}
+		return err
+	})
+	for _, t := range used {
+		if t.After(last) {
+			last = t
+		}
+	}
+	return last, err
+}
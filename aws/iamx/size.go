@@ -0,0 +1,180 @@
+package iamx
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Policy document size limits, in characters excluding whitespace.
+const (
+	ManagedPolicySize     = 6144
+	InlineRolePolicySize  = 10240
+	InlineUserPolicySize  = 2048
+	InlineGroupPolicySize = 5120
+	TrustPolicySize       = 2048
+)
+
+// PolicySize returns the size of policy document doc as counted by IAM.
+// Whitespace is ignored, except within strings.
+func PolicySize(doc string) int {
+	n, str, esc := 0, false, false
+	for _, c := range doc {
+		switch {
+		case esc:
+			esc = false
+		case str && c == '\\':
+			esc = true
+		case c == '"':
+			str = !str
+		case !str && unicode.IsSpace(c):
+			continue
+		}
+		n++
+	}
+	return n
+}
+
+// Size returns the size of the JSON representation of policy p as counted by
+// IAM. The policy version is set to PolicyVersion2012 if it is not already
+// specified.
+func (p *Policy) Size() int {
+	return PolicySize(*p.Doc())
+}
+
+// Split returns one or more copies of policy p, each of which does not exceed
+// max characters. Statements are assigned to the first policy with enough
+// space. Statements that are too large on their own are split by their Action
+// or Resource elements, and each part receives a numeric SID suffix that keeps
+// its SID unique among all statements of the original policy. Attaching
+// all returned policies to the same entity grants the same permissions as the
+// original. This does not apply to trust policies, which cannot be split. An
+// error is returned if a statement cannot be reduced to fit within max.
+func (p *Policy) Split(max int) ([]*Policy, error) {
+	c := p.Clone()
+	if c.Version == "" {
+		c.Version = PolicyVersion2012
+	}
+	if c.Size() <= max {
+		return []*Policy{c}, nil
+	}
+	stmts := c.Statement
+	c.Statement = []*Statement{}
+	base := c.Size()
+	sids := make(map[string]bool, len(stmts))
+	for _, s := range stmts {
+		sids[s.SID] = true
+	}
+	var out []*Policy
+	var sizes []int
+	for i, s := range stmts {
+		parts := splitStatement(s, max-base, sids)
+		if parts == nil {
+			return nil, fmt.Errorf("policy: statement %d cannot be split "+
+				"to fit within %d characters", i, max)
+		}
+		for _, s := range parts {
+			n := stmtSize(s)
+			j := 0
+			for ; j < len(out); j++ {
+				// Comma separator is needed for all but the first statement
+				if sizes[j]+1+n <= max {
+					break
+				}
+			}
+			if j == len(out) {
+				cp := *c
+				cp.Statement = nil
+				out, sizes = append(out, &cp), append(sizes, base-1)
+			}
+			out[j].Statement = append(out[j].Statement, s)
+			sizes[j] += 1 + n
+		}
+	}
+	return out, nil
+}
+
+// splitStatement splits statement s into parts that do not exceed max
+// characters. Only Action and Resource elements can be split without changing
+// the meaning of the statement. Each part receives a SID that is not in sids,
+// which is updated with the new SIDs. It returns nil if s cannot be split.
+func splitStatement(s *Statement, max int, sids map[string]bool) []*Statement {
+	if stmtSize(s) <= max {
+		return []*Statement{s}
+	}
+	if s.SID == "" {
+		return splitParts(s, max)
+	}
+	// Reserve space for the SID suffix, which is bounded by the maximum number
+	// of parts plus the number of SIDs that may need to be skipped.
+	n := 1
+	if len(s.Action) > 1 {
+		n *= len(s.Action)
+	}
+	if len(s.Resource) > 1 {
+		n *= len(s.Resource)
+	}
+	out := splitParts(s, max-len(strconv.Itoa(n+len(sids))))
+	k := 1
+	for _, p := range out {
+		for sids[s.SID+strconv.Itoa(k)] {
+			k++
+		}
+		p.SID = s.SID + strconv.Itoa(k)
+		sids[p.SID] = true
+	}
+	return out
+}
+
+// splitParts recursively halves statement s until all parts fit within max
+// characters. It returns nil if s cannot be split.
+func splitParts(s *Statement, max int) []*Statement {
+	if stmtSize(s) <= max {
+		return []*Statement{s}
+	}
+	parts := halve(s)
+	if parts == nil {
+		return nil
+	}
+	var out []*Statement
+	for _, p := range parts {
+		v := splitParts(p, max)
+		if v == nil {
+			return nil
+		}
+		out = append(out, v...)
+	}
+	return out
+}
+
+// halve splits the larger of the Action and Resource elements of s in half,
+// returning two new statements. It returns nil if neither element has more
+// than one value.
+func halve(s *Statement) []*Statement {
+	a, b := s.Clone(), s.Clone()
+	switch {
+	case len(s.Action) > 1 && len(s.Action) >= len(s.Resource):
+		n := len(s.Action) / 2
+		a.Action, b.Action = a.Action[:n:n], b.Action[n:]
+	case len(s.Resource) > 1:
+		n := len(s.Resource) / 2
+		a.Resource, b.Resource = a.Resource[:n:n], b.Resource[n:]
+	default:
+		return nil
+	}
+	return []*Statement{a, b}
+}
+
+// stmtSize returns the size of the JSON representation of statement s.
+func stmtSize(s *Statement) int {
+	var b strings.Builder
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(s); err != nil {
+		panic("policy: encode error: " + err.Error())
+	}
+	return utf8.RuneCountInString(strings.TrimSuffix(b.String(), "\n"))
+}
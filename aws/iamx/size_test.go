@@ -0,0 +1,120 @@
+package iamx
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPolicySize(t *testing.T) {
+	tests := []struct {
+		doc string
+		n   int
+	}{
+		{``, 0},
+		{`{}`, 2},
+		{" {\n\t\"a\" : \"b c\" } ", 11},
+		{`{"a":"\" x"}`, 12},
+		{`{"a":"é"}`, 9},
+	}
+	for _, tc := range tests {
+		assert.Equal(t, tc.n, PolicySize(tc.doc), "%q", tc.doc)
+	}
+
+	p := &Policy{Statement: []*Statement{{
+		Effect:   Allow,
+		Action:   PolicyMultiVal{"s3:GetObject"},
+		Resource: PolicyMultiVal{"*"},
+	}}}
+	want := `{"Version":"2012-10-17","Statement":[{"Effect":"Allow",` +
+		`"Action":"s3:GetObject","Resource":"*"}]}`
+	assert.Equal(t, len(want), p.Size())
+}
+
+func TestPolicySplit(t *testing.T) {
+	p := &Policy{Statement: []*Statement{{
+		Effect:   Allow,
+		Action:   PolicyMultiVal{"s3:GetObject"},
+		Resource: PolicyMultiVal{"*"},
+	}}}
+	v, err := p.Split(ManagedPolicySize)
+	require.NoError(t, err)
+	require.Len(t, v, 1)
+	assert.Equal(t, p.Doc(), v[0].Doc())
+	assert.True(t, p != v[0])
+
+	var res PolicyMultiVal
+	for i := 0; i < 200; i++ {
+		res = append(res, fmt.Sprintf("arn:aws:s3:::bucket-%03d/*", i))
+	}
+	p = &Policy{ID: "id", Statement: []*Statement{{
+		SID:      "Big",
+		Effect:   Allow,
+		Action:   PolicyMultiVal{"s3:GetObject", "s3:PutObject"},
+		Resource: res,
+	}, {
+		Effect:    Deny,
+		NotAction: PolicyMultiVal{"s3:*"},
+		Resource:  PolicyMultiVal{"*"},
+	}}}
+	require.True(t, p.Size() > 2048)
+	v, err = p.Split(2048)
+	require.NoError(t, err)
+	require.True(t, len(v) > 1)
+	var all []string
+	var deny int
+	sids := make(map[string]bool)
+	for _, q := range v {
+		assert.True(t, q.Size() <= 2048, "%d", q.Size())
+		assert.Equal(t, PolicyVersion2012, q.Version)
+		assert.Equal(t, "id", q.ID)
+		for _, s := range q.Statement {
+			if s.Effect == Deny {
+				deny++
+				continue
+			}
+			assert.False(t, sids[s.SID], "%s", s.SID)
+			sids[s.SID] = true
+			assert.Equal(t, p.Statement[0].Action, s.Action)
+			all = append(all, s.Resource...)
+		}
+	}
+	assert.Equal(t, 1, deny)
+	assert.Equal(t, []string(res), all)
+	assert.True(t, sids["Big1"])
+
+	// Split SIDs do not collide with existing ones
+	q := p.Clone()
+	q.Statement[1].SID = "Big1"
+	q.Statement = append(q.Statement, &Statement{
+		SID:      "Big3",
+		Effect:   Allow,
+		Action:   PolicyMultiVal{"s3:ListBucket"},
+		Resource: PolicyMultiVal{"*"},
+	})
+	v, err = q.Split(2048)
+	require.NoError(t, err)
+	sids = make(map[string]bool)
+	for _, q := range v {
+		assert.True(t, q.Size() <= 2048, "%d", q.Size())
+		for _, s := range q.Statement {
+			assert.False(t, sids[s.SID], "%s", s.SID)
+			sids[s.SID] = true
+		}
+	}
+	assert.True(t, sids["Big2"])
+	assert.True(t, sids["Big4"])
+
+	// Original is not modified
+	assert.Equal(t, "Big", p.Statement[0].SID)
+	assert.Len(t, p.Statement[0].Resource, 200)
+
+	// NotAction cannot be split
+	p.Statement[0].Action, p.Statement[0].NotAction = nil, res
+	p.Statement[0].Resource = PolicyMultiVal{"*"}
+	_, err = p.Split(2048)
+	assert.EqualError(t, err,
+		"policy: statement 0 cannot be split to fit within 2048 characters")
+}
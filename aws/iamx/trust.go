@@ -0,0 +1,152 @@
+package iamx
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mxk/go-cloud/aws/arn"
+	"github.com/mxk/go-cloud/aws/region"
+)
+
+// ServicePrincipalName returns the service principal of the specified service
+// (e.g. "lambda", "ec2", "ecs-tasks") in the target partition, which defaults
+// to aws. Names that already contain a '.' are returned unmodified.
+func ServicePrincipalName(partition, service string) string {
+	if strings.IndexByte(service, '.') != -1 {
+		return service
+	}
+	if partition == "" {
+		partition = "aws"
+	}
+	suffix := region.DNSSuffix(partition)
+	if suffix == "" {
+		suffix = "amazonaws.com"
+	}
+	return service + "." + suffix
+}
+
+// ServiceTrustPolicy returns a trust policy that allows the specified services
+// to assume a role in the target partition.
+func ServiceTrustPolicy(partition string, services ...string) *Policy {
+	names := make(PolicyMultiVal, len(services))
+	for i, s := range services {
+		names[i] = ServicePrincipalName(partition, s)
+	}
+	return &Policy{
+		Version: PolicyVersion2012,
+		Statement: []*Statement{{
+			Effect:    Allow,
+			Principal: &Principal{PrincipalMap: PrincipalMap{Service: names}},
+			Action:    PolicyMultiVal{"sts:AssumeRole"},
+		}},
+	}
+}
+
+// OIDCTrustPolicy returns a trust policy for web identity federation through
+// the specified OIDC provider ARN. Audience is matched exactly, and subjects
+// may contain wildcards. Empty audience and subject conditions are omitted. An
+// error is returned if provider is not a valid OIDC provider ARN.
+func OIDCTrustPolicy(provider arn.ARN, aud string, sub ...string) (*Policy,
+	error) {
+	url, err := providerName(provider, "oidc-provider")
+	if err != nil {
+		return nil, err
+	}
+	conds := make(ConditionMap)
+	if aud != "" {
+		conds.add("StringEquals", url+":aud", aud)
+	}
+	if len(sub) > 0 {
+		op := "StringEquals"
+		for _, s := range sub {
+			if strings.ContainsAny(s, "*?") {
+				op = "StringLike"
+				break
+			}
+		}
+		conds.add(op, url+":sub", sub...)
+	}
+	return federatedTrustPolicy(provider, "sts:AssumeRoleWithWebIdentity",
+		conds), nil
+}
+
+// SAMLTrustPolicy returns a trust policy for SAML federation through the
+// specified SAML provider ARN. The SAML audience is restricted to the AWS
+// sign-in endpoint of the provider's partition. An error is returned if
+// provider is not a valid SAML provider ARN.
+func SAMLTrustPolicy(provider arn.ARN) (*Policy, error) {
+	if _, err := providerName(provider, "saml-provider"); err != nil {
+		return nil, err
+	}
+	suffix := "aws.amazon.com"
+	switch provider.Partition() {
+	case "aws-cn":
+		suffix = "amazonaws.cn"
+	case "aws-us-gov":
+		suffix = "amazonaws-us-gov.com"
+	}
+	conds := make(ConditionMap)
+	conds.add("StringEquals", "SAML:aud", "https://signin."+suffix+"/saml")
+	return federatedTrustPolicy(provider, "sts:AssumeRoleWithSAML",
+		conds), nil
+}
+
+// CrossAccountTrustPolicy returns a trust policy that allows the specified AWS
+// principals to assume a role. If externalID is not empty, callers must
+// provide it via sts:ExternalId. If mfa is true, callers must authenticate
+// with MFA.
+func CrossAccountTrustPolicy(externalID string, mfa bool,
+	principals ...string) *Policy {
+	p := AssumeRolePolicy(Allow, principals...)
+	conds := make(ConditionMap)
+	if externalID != "" {
+		conds.add("StringEquals", "sts:ExternalId", externalID)
+	}
+	if mfa {
+		conds.add("Bool", "aws:MultiFactorAuthPresent", "true")
+	}
+	if len(conds) > 0 {
+		p.Statement[0].Condition = conds
+	}
+	return p
+}
+
+// providerName validates an IAM identity provider ARN of the specified resource
+// type and returns the provider name that follows the type.
+func providerName(provider arn.ARN, typ string) (string, error) {
+	r, err := arn.Parse(string(provider))
+	if err != nil {
+		return "", err
+	}
+	name := strings.TrimPrefix(r.Resource(), typ+"/")
+	if r.Service() != "iam" || name == r.Resource() || name == "" {
+		return "", fmt.Errorf("iamx: invalid %s ARN %q", typ, provider)
+	}
+	return name, nil
+}
+
+// federatedTrustPolicy returns a trust policy for a federated principal.
+func federatedTrustPolicy(provider arn.ARN, action string,
+	conds ConditionMap) *Policy {
+	s := &Statement{
+		Effect: Allow,
+		Principal: &Principal{PrincipalMap: PrincipalMap{
+			Federated: PolicyMultiVal{string(provider)},
+		}},
+		Action: PolicyMultiVal{action},
+	}
+	if len(conds) > 0 {
+		s.Condition = conds
+	}
+	return &Policy{Version: PolicyVersion2012, Statement: []*Statement{s}}
+}
+
+// add adds a condition to m.
+func (m ConditionMap) add(op, key string, vals ...string) {
+	c := m[op]
+	if c == nil {
+		c = make(Conditions)
+		m[op] = c
+	}
+	c[key] = append(c[key], vals...)
+}
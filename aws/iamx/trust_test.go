@@ -0,0 +1,127 @@
+package iamx
+
+import (
+	"testing"
+
+	"github.com/mxk/go-cloud/aws/arn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServicePrincipalName(t *testing.T) {
+	assert.Equal(t, "lambda.amazonaws.com", ServicePrincipalName("", "lambda"))
+	assert.Equal(t, "ec2.amazonaws.com.cn", ServicePrincipalName("aws-cn", "ec2"))
+	assert.Equal(t, "ecs-tasks.amazonaws.com",
+		ServicePrincipalName("aws-us-gov", "ecs-tasks"))
+	assert.Equal(t, "x.example.com", ServicePrincipalName("aws-cn",
+		"x.example.com"))
+}
+
+func TestTrustPolicies(t *testing.T) {
+	must := func(p *Policy, err error) *Policy {
+		require.NoError(t, err)
+		return p
+	}
+	tests := []struct {
+		p   *Policy
+		doc string
+	}{{
+		p: ServiceTrustPolicy("", "lambda", "ecs-tasks"),
+		doc: `{"Version":"2012-10-17","Statement":[{"Effect":"Allow",` +
+			`"Principal":{"Service":["lambda.amazonaws.com",` +
+			`"ecs-tasks.amazonaws.com"]},"Action":"sts:AssumeRole"}]}`,
+	}, {
+		p: ServiceTrustPolicy("aws-cn", "ec2"),
+		doc: `{"Version":"2012-10-17","Statement":[{"Effect":"Allow",` +
+			`"Principal":{"Service":"ec2.amazonaws.com.cn"},` +
+			`"Action":"sts:AssumeRole"}]}`,
+	}, {
+		p: must(OIDCTrustPolicy("arn:aws:iam::123456789012:oidc-provider/"+
+			"token.actions.githubusercontent.com", "sts.amazonaws.com",
+			"repo:org/repo:*")),
+		doc: `{"Version":"2012-10-17","Statement":[{"Effect":"Allow",` +
+			`"Principal":{"Federated":"arn:aws:iam::123456789012:` +
+			`oidc-provider/token.actions.githubusercontent.com"},` +
+			`"Action":"sts:AssumeRoleWithWebIdentity","Condition":{` +
+			`"StringEquals":{"token.actions.githubusercontent.com:aud":` +
+			`"sts.amazonaws.com"},"StringLike":{` +
+			`"token.actions.githubusercontent.com:sub":"repo:org/repo:*"}}}]}`,
+	}, {
+		p: must(OIDCTrustPolicy(
+			"arn:aws:iam::123456789012:oidc-provider/x.com/id", "", "a", "b")),
+		doc: `{"Version":"2012-10-17","Statement":[{"Effect":"Allow",` +
+			`"Principal":{"Federated":"arn:aws:iam::123456789012:` +
+			`oidc-provider/x.com/id"},` +
+			`"Action":"sts:AssumeRoleWithWebIdentity","Condition":{` +
+			`"StringEquals":{"x.com/id:sub":["a","b"]}}}]}`,
+	}, {
+		p: must(OIDCTrustPolicy(
+			"arn:aws:iam::123456789012:oidc-provider/x.com", "")),
+		doc: `{"Version":"2012-10-17","Statement":[{"Effect":"Allow",` +
+			`"Principal":{"Federated":"arn:aws:iam::123456789012:` +
+			`oidc-provider/x.com"},` +
+			`"Action":"sts:AssumeRoleWithWebIdentity"}]}`,
+	}, {
+		p: must(SAMLTrustPolicy(
+			"arn:aws:iam::123456789012:saml-provider/idp")),
+		doc: `{"Version":"2012-10-17","Statement":[{"Effect":"Allow",` +
+			`"Principal":{"Federated":"arn:aws:iam::123456789012:` +
+			`saml-provider/idp"},"Action":"sts:AssumeRoleWithSAML",` +
+			`"Condition":{"StringEquals":{"SAML:aud":` +
+			`"https://signin.aws.amazon.com/saml"}}}]}`,
+	}, {
+		p: must(SAMLTrustPolicy(
+			"arn:aws-cn:iam::123456789012:saml-provider/idp")),
+		doc: `{"Version":"2012-10-17","Statement":[{"Effect":"Allow",` +
+			`"Principal":{"Federated":"arn:aws-cn:iam::123456789012:` +
+			`saml-provider/idp"},"Action":"sts:AssumeRoleWithSAML",` +
+			`"Condition":{"StringEquals":{"SAML:aud":` +
+			`"https://signin.amazonaws.cn/saml"}}}]}`,
+	}, {
+		p: CrossAccountTrustPolicy("ext", true, "123456789012"),
+		doc: `{"Version":"2012-10-17","Statement":[{"Effect":"Allow",` +
+			`"Principal":{"AWS":"123456789012"},"Action":"sts:AssumeRole",` +
+			`"Condition":{"Bool":{"aws:MultiFactorAuthPresent":"true"},` +
+			`"StringEquals":{"sts:ExternalId":"ext"}}}]}`,
+	}, {
+		p: CrossAccountTrustPolicy("", false, "123456789012"),
+		doc: `{"Version":"2012-10-17","Statement":[{"Effect":"Allow",` +
+			`"Principal":{"AWS":"123456789012"},"Action":"sts:AssumeRole"}]}`,
+	}}
+	for _, tc := range tests {
+		assert.Equal(t, tc.doc, *tc.p.Doc())
+	}
+
+	// Conditions are enforced
+	p := CrossAccountTrustPolicy("ext", true, "123456789012")
+	q := &Request{
+		Action:    "sts:AssumeRole",
+		Principal: "arn:aws:iam::123456789012:user/u",
+		Context: Context{
+			"sts:ExternalId":             {"ext"},
+			"aws:MultiFactorAuthPresent": {"true"},
+		},
+	}
+	d, _, err := Eval(q, p)
+	assert.NoError(t, err)
+	assert.Equal(t, Allowed, d)
+	q.Context["aws:MultiFactorAuthPresent"] = []string{"false"}
+	d, _, err = Eval(q, p)
+	assert.NoError(t, err)
+	assert.Equal(t, ImplicitDeny, d)
+
+	// Invalid providers
+	for _, r := range []arn.ARN{
+		"",
+		"x.com",
+		"arn:aws:iam::123456789012:oidc-provider/",
+		"arn:aws:iam::123456789012:saml-provider/x.com",
+		"arn:aws:sts::123456789012:oidc-provider/x.com",
+	} {
+		_, err := OIDCTrustPolicy(r, "aud")
+		assert.Error(t, err, "%s", r)
+	}
+	_, err = SAMLTrustPolicy("arn:aws:iam::123456789012:oidc-provider/idp")
+	assert.EqualError(t, err, "iamx: invalid saml-provider ARN "+
+		`"arn:aws:iam::123456789012:oidc-provider/idp"`)
+}
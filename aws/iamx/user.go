@@ -3,6 +3,8 @@ package iamx
 import (
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/iam"
+	"github.com/mxk/go-cloud/aws/arn"
+	"github.com/mxk/go-cloud/aws/awsx"
 	"github.com/mxk/go-fast"
 )
 
@@ -19,32 +21,45 @@ func (Client) GobEncode() ([]byte, error) { return nil, nil }
 // GobDecode prevents the client from being decoded by gob.
 func (Client) GobDecode([]byte) error { return nil }
 
-// DeleteUsers deletes all users under the specified IAM path.
-func (c Client) DeleteUsers(path string) error {
-	in := iam.ListUsersInput{PathPrefix: aws.String(path)}
-	r := c.ListUsersRequest(&in)
-	p := r.Paginate()
-	var users []string
-	for p.Next() {
-		out := p.CurrentPage().Users
-		for i := range out {
-			users = append(users, aws.StringValue(out[i].UserName))
-		}
-	}
-	if err := p.Err(); err != nil {
+// DeleteUsers deletes all users under the specified IAM path. If any selectors
+// are specified, only the users matching all of them are deleted.
+func (c Client) DeleteUsers(path string, opts ...DeleteOption) error {
+	users, err := c.selectUsers(path, getDeleteOpts(opts).sel)
+	if err != nil {
 		return err
 	}
 	return fast.ForEachIO(len(users), func(i int) error {
-		return c.DeleteUser(users[i])
+		return c.DeleteUser(aws.StringValue(users[i].UserName))
 	})
 }
 
 // DeleteUser deletes the specified user, ensuring that all prerequisites for
-// deletion are met.
+// deletion are met. This includes removing the user from all groups and
+// deleting all credentials, such as the login profile, MFA devices, and SSH
+// keys. Hardware MFA devices are deactivated, but not deleted.
 func (c Client) DeleteUser(name string) error {
 	err := fast.Call(
 		func() error { return c.detachUserPolicies(name) },
+		func() error { return c.deleteUserPolicies(name) },
+		func() error { return c.removeUserFromGroups(name) },
 		func() error { return c.deleteAccessKeys(name) },
+		func() error { return c.deleteLoginProfile(name) },
+		func() error { return c.deleteMFADevices(name) },
+		func() error { return c.deleteSSHPublicKeys(name) },
+		func() error { return c.deleteServiceSpecificCredentials(name) },
+		func() error { return c.deleteSigningCertificates(name) },
+		func() error {
+			in := iam.GetUserInput{UserName: aws.String(name)}
+			out, err := c.GetUserRequest(&in).Send()
+			if err != nil || out.User.PermissionsBoundary == nil {
+				return err
+			}
+			del := iam.DeleteUserPermissionsBoundaryInput{
+				UserName: aws.String(name),
+			}
+			_, err = c.DeleteUserPermissionsBoundaryRequest(&del).Send()
+			return ignoreNoSuchEntity(err)
+		},
 	)
 	if err == nil {
 		in := iam.DeleteUserInput{UserName: aws.String(name)}
@@ -53,33 +68,79 @@ func (c Client) DeleteUser(name string) error {
 	return err
 }
 
+// listUsers returns all users under the specified IAM path.
+func (c Client) listUsers(path string) ([]iam.User, error) {
+	in := iam.ListUsersInput{PathPrefix: aws.String(path)}
+	r := c.ListUsersRequest(&in)
+	p := r.Paginate()
+	var users []iam.User
+	for p.Next() {
+		users = append(users, p.CurrentPage().Users...)
+	}
+	return users, p.Err()
+}
+
 // deleteAccessKeys deletes all user access keys.
 func (c Client) deleteAccessKeys(user string) error {
+	ids, err := c.accessKeyIDs(user)
+	if err != nil {
+		return err
+	}
+	return fast.ForEachIO(len(ids), func(i int) error {
+		return c.deleteAccessKey(user, ids[i])
+	})
+}
+
+// deleteAccessKey deletes a user access key.
+func (c Client) deleteAccessKey(user, id string) error {
+	in := iam.DeleteAccessKeyInput{
+		AccessKeyId: aws.String(id),
+		UserName:    aws.String(user),
+	}
+	_, err := c.DeleteAccessKeyRequest(&in).Send()
+	return err
+}
+
+// accessKeyIDs returns the IDs of all user access keys.
+func (c Client) accessKeyIDs(user string) ([]string, error) {
+	keys, err := c.accessKeys(user)
+	ids := make([]string, len(keys))
+	for i := range keys {
+		ids[i] = aws.StringValue(keys[i].AccessKeyId)
+	}
+	return ids, err
+}
+
+// accessKeys returns metadata of all user access keys.
+func (c Client) accessKeys(user string) ([]iam.AccessKeyMetadata, error) {
 	in := iam.ListAccessKeysInput{UserName: aws.String(user)}
 	r := c.ListAccessKeysRequest(&in)
 	p := r.Paginate()
-	var ids []string
+	var keys []iam.AccessKeyMetadata
 	for p.Next() {
-		out := p.CurrentPage().AccessKeyMetadata
-		for i := range out {
-			ids = append(ids, aws.StringValue(out[i].AccessKeyId))
-		}
+		keys = append(keys, p.CurrentPage().AccessKeyMetadata...)
 	}
-	if err := p.Err(); err != nil {
+	return keys, p.Err()
+}
+
+// detachUserPolicies detaches all user policies.
+func (c Client) detachUserPolicies(user string) error {
+	arns, err := c.attachedUserPolicies(user)
+	if err != nil {
 		return err
 	}
-	return fast.ForEachIO(len(ids), func(i int) error {
-		in := iam.DeleteAccessKeyInput{
-			AccessKeyId: aws.String(ids[i]),
-			UserName:    aws.String(user),
+	return fast.ForEachIO(len(arns), func(i int) error {
+		in := iam.DetachUserPolicyInput{
+			PolicyArn: aws.String(arns[i]),
+			UserName:  aws.String(user),
 		}
-		_, err := c.DeleteAccessKeyRequest(&in).Send()
+		_, err := c.DetachUserPolicyRequest(&in).Send()
 		return err
 	})
 }
 
-// detachUserPolicies detaches all user policies.
-func (c Client) detachUserPolicies(user string) error {
+// attachedUserPolicies returns the ARNs of all managed user policies.
+func (c Client) attachedUserPolicies(user string) ([]string, error) {
 	in := iam.ListAttachedUserPoliciesInput{UserName: aws.String(user)}
 	r := c.ListAttachedUserPoliciesRequest(&in)
 	p := r.Paginate()
@@ -90,15 +151,228 @@ func (c Client) detachUserPolicies(user string) error {
 			arns = append(arns, aws.StringValue(out[i].PolicyArn))
 		}
 	}
-	if err := p.Err(); err != nil {
+	return arns, p.Err()
+}
+
+// deleteUserPolicies deletes all inline user policies.
+func (c Client) deleteUserPolicies(user string) error {
+	names, err := c.userPolicyNames(user)
+	if err != nil {
 		return err
 	}
-	return fast.ForEachIO(len(arns), func(i int) error {
-		in := iam.DetachUserPolicyInput{
-			PolicyArn: aws.String(arns[i]),
-			UserName:  aws.String(user),
+	return fast.ForEachIO(len(names), func(i int) error {
+		in := iam.DeleteUserPolicyInput{
+			PolicyName: aws.String(names[i]),
+			UserName:   aws.String(user),
 		}
-		_, err := c.DetachUserPolicyRequest(&in).Send()
+		_, err := c.DeleteUserPolicyRequest(&in).Send()
 		return err
 	})
 }
+
+// userPolicyNames returns the names of all inline user policies.
+func (c Client) userPolicyNames(user string) ([]string, error) {
+	in := iam.ListUserPoliciesInput{UserName: aws.String(user)}
+	r := c.ListUserPoliciesRequest(&in)
+	p := r.Paginate()
+	var names []string
+	for p.Next() {
+		names = append(names, p.CurrentPage().PolicyNames...)
+	}
+	return names, p.Err()
+}
+
+// removeUserFromGroups removes the user from all groups.
+func (c Client) removeUserFromGroups(user string) error {
+	groups, err := c.userGroups(user)
+	if err != nil {
+		return err
+	}
+	return fast.ForEachIO(len(groups), func(i int) error {
+		return c.removeUserFromGroup(user, groups[i])
+	})
+}
+
+// userGroups returns the names of all groups containing the user.
+func (c Client) userGroups(user string) ([]string, error) {
+	in := iam.ListGroupsForUserInput{UserName: aws.String(user)}
+	r := c.ListGroupsForUserRequest(&in)
+	p := r.Paginate()
+	var groups []string
+	for p.Next() {
+		out := p.CurrentPage().Groups
+		for i := range out {
+			groups = append(groups, aws.StringValue(out[i].GroupName))
+		}
+	}
+	return groups, p.Err()
+}
+
+// removeUserFromGroup removes the user from the specified group.
+func (c Client) removeUserFromGroup(user, group string) error {
+	in := iam.RemoveUserFromGroupInput{
+		GroupName: aws.String(group),
+		UserName:  aws.String(user),
+	}
+	_, err := c.RemoveUserFromGroupRequest(&in).Send()
+	return err
+}
+
+// deleteLoginProfile deletes the user password, if one exists.
+func (c Client) deleteLoginProfile(user string) error {
+	in := iam.DeleteLoginProfileInput{UserName: aws.String(user)}
+	_, err := c.DeleteLoginProfileRequest(&in).Send()
+	return ignoreNoSuchEntity(err)
+}
+
+// hasLoginProfile returns true if the user has a password.
+func (c Client) hasLoginProfile(user string) (bool, error) {
+	in := iam.GetLoginProfileInput{UserName: aws.String(user)}
+	_, err := c.GetLoginProfileRequest(&in).Send()
+	if err != nil {
+		return false, ignoreNoSuchEntity(err)
+	}
+	return true, nil
+}
+
+// deleteMFADevices deactivates all user MFA devices and deletes virtual ones.
+func (c Client) deleteMFADevices(user string) error {
+	serials, err := c.mfaSerials(user)
+	if err != nil {
+		return err
+	}
+	return fast.ForEachIO(len(serials), func(i int) error {
+		in := iam.DeactivateMFADeviceInput{
+			SerialNumber: aws.String(serials[i]),
+			UserName:     aws.String(user),
+		}
+		_, err := c.DeactivateMFADeviceRequest(&in).Send()
+		if err != nil || !arn.ARN(serials[i]).Valid() {
+			return err
+		}
+		del := iam.DeleteVirtualMFADeviceInput{
+			SerialNumber: aws.String(serials[i]),
+		}
+		_, err = c.DeleteVirtualMFADeviceRequest(&del).Send()
+		return err
+	})
+}
+
+// mfaSerials returns the serial numbers of all user MFA devices. Virtual
+// device serial numbers are ARNs.
+func (c Client) mfaSerials(user string) ([]string, error) {
+	in := iam.ListMFADevicesInput{UserName: aws.String(user)}
+	r := c.ListMFADevicesRequest(&in)
+	p := r.Paginate()
+	var serials []string
+	for p.Next() {
+		out := p.CurrentPage().MFADevices
+		for i := range out {
+			serials = append(serials, aws.StringValue(out[i].SerialNumber))
+		}
+	}
+	return serials, p.Err()
+}
+
+// deleteSSHPublicKeys deletes all user SSH public keys.
+func (c Client) deleteSSHPublicKeys(user string) error {
+	ids, err := c.sshPublicKeyIDs(user)
+	if err != nil {
+		return err
+	}
+	return fast.ForEachIO(len(ids), func(i int) error {
+		in := iam.DeleteSSHPublicKeyInput{
+			SSHPublicKeyId: aws.String(ids[i]),
+			UserName:       aws.String(user),
+		}
+		_, err := c.DeleteSSHPublicKeyRequest(&in).Send()
+		return err
+	})
+}
+
+// sshPublicKeyIDs returns the IDs of all user SSH public keys.
+func (c Client) sshPublicKeyIDs(user string) ([]string, error) {
+	in := iam.ListSSHPublicKeysInput{UserName: aws.String(user)}
+	r := c.ListSSHPublicKeysRequest(&in)
+	p := r.Paginate()
+	var ids []string
+	for p.Next() {
+		out := p.CurrentPage().SSHPublicKeys
+		for i := range out {
+			ids = append(ids, aws.StringValue(out[i].SSHPublicKeyId))
+		}
+	}
+	return ids, p.Err()
+}
+
+// deleteServiceSpecificCredentials deletes all user service-specific
+// credentials, such as CodeCommit HTTPS Git credentials.
+func (c Client) deleteServiceSpecificCredentials(user string) error {
+	ids, err := c.serviceSpecificCredentialIDs(user)
+	if err != nil {
+		return err
+	}
+	return fast.ForEachIO(len(ids), func(i int) error {
+		in := iam.DeleteServiceSpecificCredentialInput{
+			ServiceSpecificCredentialId: aws.String(ids[i]),
+			UserName:                    aws.String(user),
+		}
+		_, err := c.DeleteServiceSpecificCredentialRequest(&in).Send()
+		return err
+	})
+}
+
+// serviceSpecificCredentialIDs returns the IDs of all user service-specific
+// credentials.
+func (c Client) serviceSpecificCredentialIDs(user string) ([]string, error) {
+	in := iam.ListServiceSpecificCredentialsInput{UserName: aws.String(user)}
+	out, err := c.ListServiceSpecificCredentialsRequest(&in).Send()
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]string, len(out.ServiceSpecificCredentials))
+	for i := range ids {
+		ids[i] = aws.StringValue(
+			out.ServiceSpecificCredentials[i].ServiceSpecificCredentialId)
+	}
+	return ids, nil
+}
+
+// deleteSigningCertificates deletes all user signing certificates.
+func (c Client) deleteSigningCertificates(user string) error {
+	ids, err := c.signingCertificateIDs(user)
+	if err != nil {
+		return err
+	}
+	return fast.ForEachIO(len(ids), func(i int) error {
+		in := iam.DeleteSigningCertificateInput{
+			CertificateId: aws.String(ids[i]),
+			UserName:      aws.String(user),
+		}
+		_, err := c.DeleteSigningCertificateRequest(&in).Send()
+		return err
+	})
+}
+
+// signingCertificateIDs returns the IDs of all user signing certificates.
+func (c Client) signingCertificateIDs(user string) ([]string, error) {
+	in := iam.ListSigningCertificatesInput{UserName: aws.String(user)}
+	r := c.ListSigningCertificatesRequest(&in)
+	p := r.Paginate()
+	var ids []string
+	for p.Next() {
+		out := p.CurrentPage().Certificates
+		for i := range out {
+			ids = append(ids, aws.StringValue(out[i].CertificateId))
+		}
+	}
+	return ids, p.Err()
+}
+
+// ignoreNoSuchEntity returns nil if err is a NoSuchEntity error.
+func ignoreNoSuchEntity(err error) error {
+	if awsx.ErrCode(err) == iam.ErrCodeNoSuchEntityException {
+		err = nil
+	}
+	return err
+}
@@ -0,0 +1,150 @@
+package iamx
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// UndefinedVarError lists policy variables that are not defined in the
+// request context and do not specify a default value.
+type UndefinedVarError []string
+
+// Error implements error interface.
+func (e UndefinedVarError) Error() string {
+	return "policy: undefined variable(s): " + strings.Join(e, ", ")
+}
+
+// Expand returns a copy of policy p with all variables in Resource,
+// NotResource, and Condition values replaced by their context values.
+// Variables use the "${key}" or "${key, 'default'}" syntax. The special
+// variables "${*}", "${?}", and "${$}" produce literal '*', '?', and '$'
+// characters in condition values that are compared exactly. Resource and
+// NotResource elements and the values of StringLike and Arn* conditions are
+// patterns, so these escapes are left unexpanded to avoid turning them into
+// wildcards. Pattern matching treats the escapes as literal characters (see
+// arn.Glob). All variables that cannot be resolved are reported via
+// UndefinedVarError.
+func (p *Policy) Expand(ctx Context) (*Policy, error) {
+	x := varExpander{ctx: ctx}
+	c := p.Clone()
+	for _, s := range c.Statement {
+		x.pattern = true
+		x.expand(s.Resource)
+		x.expand(s.NotResource)
+		for op, conds := range s.Condition {
+			x.pattern = patternOp(op)
+			for _, v := range conds {
+				x.expand(v)
+			}
+		}
+	}
+	if x.err != nil {
+		return nil, x.err
+	}
+	if len(x.undef) > 0 {
+		sort.Strings(x.undef)
+		return nil, x.undef
+	}
+	return c, nil
+}
+
+// varExpander replaces policy variables with context values.
+type varExpander struct {
+	ctx     Context
+	undef   UndefinedVarError
+	err     error
+	pattern bool // Keep "${*}", "${?}", and "${$}" escapes
+}
+
+// expand replaces variables in all entries of v.
+func (x *varExpander) expand(v PolicyMultiVal) {
+	for i, s := range v {
+		v[i] = x.expandString(s)
+	}
+}
+
+// expandString replaces variables in s.
+func (x *varExpander) expandString(s string) string {
+	i := strings.Index(s, "${")
+	if i == -1 {
+		return s
+	}
+	var b strings.Builder
+	for i != -1 {
+		b.WriteString(s[:i])
+		n := strings.IndexByte(s[i:], '}')
+		if n == -1 {
+			x.setErr(fmt.Errorf("policy: unterminated variable in %q", s))
+			return s
+		}
+		n += i + 1
+		if v, ok := x.lookup(s[i+2 : n-1]); ok {
+			b.WriteString(v)
+		} else {
+			b.WriteString(s[i:n])
+		}
+		s = s[n:]
+		i = strings.Index(s, "${")
+	}
+	b.WriteString(s)
+	return b.String()
+}
+
+// lookup returns the value of variable spec, which is the text between "${"
+// and "}".
+func (x *varExpander) lookup(spec string) (string, bool) {
+	switch spec {
+	case "*", "?", "$":
+		return spec, !x.pattern
+	}
+	name, def, hasDef := spec, "", false
+	if i := strings.IndexByte(spec, ','); i != -1 {
+		name, def = spec[:i], strings.TrimSpace(spec[i+1:])
+		if len(def) < 2 || def[0] != '\'' || def[len(def)-1] != '\'' {
+			x.setErr(fmt.Errorf("policy: invalid variable default in %q",
+				"${"+spec+"}"))
+			return "", false
+		}
+		def, hasDef = def[1:len(def)-1], true
+	}
+	if name = strings.TrimSpace(name); name == "" {
+		x.setErr(fmt.Errorf("policy: empty variable name in %q",
+			"${"+spec+"}"))
+		return "", false
+	}
+	switch v, ok := x.ctx.Get(name); {
+	case len(v) > 1:
+		x.setErr(fmt.Errorf("policy: multi-valued variable %q", name))
+		return "", false
+	case ok:
+		return v[0], true
+	case hasDef:
+		return def, true
+	}
+	for _, u := range x.undef {
+		if u == name {
+			return "", false
+		}
+	}
+	x.undef = append(x.undef, name)
+	return "", false
+}
+
+// patternOp returns true if the values of condition operator op are patterns.
+// Unknown operators are treated as patterns.
+func patternOp(op string) bool {
+	o, err := parseCondOp(op)
+	if err != nil {
+		return true
+	}
+	base := strings.TrimSuffix(op[len(o.set):], ifExists)
+	return strings.HasSuffix(base, "Like") || strings.HasPrefix(base, "Arn")
+}
+
+// setErr records the first expansion error.
+func (x *varExpander) setErr(err error) {
+	if x.err == nil {
+		x.err = err
+	}
+}
@@ -0,0 +1,113 @@
+package iamx
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpand(t *testing.T) {
+	p := &Policy{Statement: []*Statement{{
+		Effect: Allow,
+		Action: PolicyMultiVal{"s3:${x}"},
+		Resource: PolicyMultiVal{
+			"arn:aws:s3:::home/${aws:username}/*",
+			"arn:aws:s3:::${aws:PrincipalTag/team}-${aws:username}",
+			"arn:aws:s3:::${*}${?}${$}",
+		},
+		Condition: ConditionMap{
+			"StringLike": {
+				"s3:prefix": {"${aws:username}/${ aws:userid , 'none' }"},
+			},
+			"StringEquals":                {"s3:delimiter": {"${*}${?}${$}"}},
+			"ForAnyValue:ArnLikeIfExists": {"aws:SourceArn": {"${*}"}},
+		},
+	}}}
+	ctx := Context{
+		"AWS:UserName":          {"alice"},
+		"aws:PrincipalTag/team": {"dev"},
+	}
+	x, err := p.Expand(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, PolicyMultiVal{"s3:${x}"}, x.Statement[0].Action)
+	assert.Equal(t, PolicyMultiVal{
+		"arn:aws:s3:::home/alice/*",
+		"arn:aws:s3:::dev-alice",
+		"arn:aws:s3:::${*}${?}${$}",
+	}, x.Statement[0].Resource)
+	assert.Equal(t, PolicyMultiVal{"alice/none"},
+		x.Statement[0].Condition["StringLike"]["s3:prefix"])
+	assert.Equal(t, PolicyMultiVal{"*?$"},
+		x.Statement[0].Condition["StringEquals"]["s3:delimiter"])
+	assert.Equal(t, PolicyMultiVal{"${*}"},
+		x.Statement[0].Condition["ForAnyValue:ArnLikeIfExists"]["aws:SourceArn"])
+	assert.Equal(t, "arn:aws:s3:::home/${aws:username}/*",
+		p.Statement[0].Resource[0])
+
+	_, err = p.Expand(Context{"aws:userid": {"x"}})
+	assert.Equal(t, UndefinedVarError{"aws:PrincipalTag/team", "aws:username"},
+		err)
+	assert.EqualError(t, err, "policy: undefined variable(s): "+
+		"aws:PrincipalTag/team, aws:username")
+}
+
+func TestExpandEscapes(t *testing.T) {
+	p := &Policy{Statement: []*Statement{{
+		Effect:   Allow,
+		Action:   PolicyMultiVal{"s3:GetObject"},
+		Resource: PolicyMultiVal{"arn:aws:s3:::b/${*}"},
+		Condition: ConditionMap{"StringLike": {
+			"aws:UserAgent": {"${?}*"},
+		}},
+	}}}
+	x, err := p.Expand(nil)
+	require.NoError(t, err)
+	tests := []*struct {
+		resource, ua string
+		want         Decision
+	}{
+		{"arn:aws:s3:::b/*", "?curl", Allowed},
+		{"arn:aws:s3:::b/*", "?", Allowed},
+		{"arn:aws:s3:::b/x", "?curl", ImplicitDeny},
+		{"arn:aws:s3:::b/${*}", "?curl", ImplicitDeny},
+		{"arn:aws:s3:::b/${zzz}", "?curl", ImplicitDeny},
+		{"arn:aws:s3:::b/*", "curl", ImplicitDeny},
+	}
+	for _, tc := range tests {
+		q := &Request{
+			Action:   "s3:GetObject",
+			Resource: tc.resource,
+			Context:  Context{"aws:UserAgent": {tc.ua}},
+		}
+		for _, pol := range []*Policy{p, x} {
+			d, _, err := Eval(q, pol)
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, d, "%+v", tc)
+		}
+	}
+}
+
+func TestExpandError(t *testing.T) {
+	for _, s := range []string{
+		"${aws:username",
+		"${}",
+		"${ , 'x'}",
+		"${aws:username, x}",
+		"${aws:username, '}",
+		"${aws:TagKeys}",
+	} {
+		p := &Policy{Statement: []*Statement{{Resource: PolicyMultiVal{s}}}}
+		_, err := p.Expand(Context{
+			"aws:username": {"alice"},
+			"aws:TagKeys":  {"a", "b"},
+		})
+		assert.Error(t, err, "%s", s)
+		_, isUndef := err.(UndefinedVarError)
+		assert.False(t, isUndef, "%s", s)
+	}
+	p := &Policy{Statement: []*Statement{{Resource: PolicyMultiVal{"${a, ''}"}}}}
+	x, err := p.Expand(nil)
+	require.NoError(t, err)
+	assert.Equal(t, "", x.Statement[0].Resource[0])
+}
@@ -2,7 +2,9 @@
 package region
 
 import (
+	"net/url"
 	"sort"
+	"strings"
 	"sync"
 
 	"github.com/aws/aws-sdk-go-v2/aws/endpoints"
@@ -13,6 +15,8 @@ var (
 	regionPart  map[string]string
 	partRegions map[string][]string
 	svcRegions  map[string][]string
+	partSuffix  map[string]string
+	suffixes    []string
 )
 
 // Partitions returns all known partitions.
@@ -32,6 +36,13 @@ func Partition(region string) string {
 	return regionPart[region]
 }
 
+// DNSSuffix returns the DNS suffix of the specified partition (e.g.
+// "amazonaws.com.cn" for aws-cn).
+func DNSSuffix(partition string) string {
+	once.Do(load)
+	return partSuffix[partition]
+}
+
 // Related returns all regions in a partition, which may be specified explicitly
 // by name or implicitly by one of its regions.
 func Related(partOrRegion string) []string {
@@ -73,6 +84,7 @@ func load() {
 	regionPart = make(map[string]string)
 	partRegions = make(map[string][]string, len(parts))
 	svcRegions = make(map[string][]string)
+	partSuffix = make(map[string]string, len(parts))
 	for _, p := range parts {
 		regionSet := make(map[string]struct{})
 		// Using Endpoints() instead of Regions() to handle global services
@@ -106,10 +118,41 @@ func load() {
 		}
 		sort.Strings(prs)
 		partRegions[pid] = prs
+		partSuffix[pid] = dnsSuffix(p, prs)
 	}
 	for _, sr := range svcRegions {
 		sort.Strings(sr)
 	}
+	for _, s := range partSuffix {
+		if s != "" && !contains(suffixes, s) {
+			suffixes = append(suffixes, s)
+			sort.Strings(suffixes)
+		}
+	}
+	// Longest suffix first to match "amazonaws.com.cn" before "amazonaws.com"
+	sort.Slice(suffixes, func(i, j int) bool {
+		return len(suffixes[i]) > len(suffixes[j])
+	})
+}
+
+// dnsSuffix determines partition DNS suffix from the STS endpoint of its first
+// regular region.
+func dnsSuffix(p endpoints.Partition, regions []string) string {
+	for _, r := range regions {
+		ep, err := p.Endpoint("sts", r, endpoints.ResolveOptions{})
+		if err != nil {
+			continue
+		}
+		u, err := url.Parse(ep.URL)
+		if err != nil {
+			continue
+		}
+		host := "sts." + r + "."
+		if strings.HasPrefix(u.Host, host) {
+			return u.Host[len(host):]
+		}
+	}
+	return ""
 }
 
 // regionsIn returns a copy of all regions in partition p.
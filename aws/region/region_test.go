@@ -40,3 +40,10 @@ func TestSubset(t *testing.T) {
 	assert.Equal(t, []string(nil), Subset("no-such-partition", "iam"))
 	assert.Contains(t, Subset("aws-us-gov", "ec2"), "us-gov-west-1")
 }
+
+func TestDNSSuffix(t *testing.T) {
+	assert.Equal(t, "amazonaws.com", DNSSuffix("aws"))
+	assert.Equal(t, "amazonaws.com.cn", DNSSuffix("aws-cn"))
+	assert.Equal(t, "amazonaws.com", DNSSuffix("aws-us-gov"))
+	assert.Equal(t, "", DNSSuffix("no-such-partition"))
+}
@@ -0,0 +1,97 @@
+package region
+
+import (
+	"strings"
+
+	"github.com/mxk/go-cloud/aws/arn"
+)
+
+// UnmappedError lists values that could not be translated to another
+// partition.
+type UnmappedError struct {
+	Partition string
+	Values    []string
+}
+
+// Error implements error interface.
+func (e *UnmappedError) Error() string {
+	return "region: cannot translate to " + e.Partition + ": " +
+		strings.Join(e.Values, ", ")
+}
+
+// Translator converts partition-specific values, such as ARNs, regions, and
+// service principals, to the target partition.
+type Translator struct {
+	// Partition is the target partition.
+	Partition string
+
+	// Regions maps regions outside of the target partition to regions within
+	// it (e.g. "us-east-1" to "cn-north-1"). Regions that are already in the
+	// target partition do not need to be mapped.
+	Regions map[string]string
+
+	// ManagedPolicies contains the names, including any path after "policy/",
+	// of AWS managed policies that are known to exist in the target partition
+	// (e.g. "AdministratorAccess" or "service-role/AWSConfigRole"). AWS
+	// managed policies are not always available in every partition, so the
+	// ARNs of other policies are not translated.
+	ManagedPolicies map[string]bool
+}
+
+// Region returns the target partition region that corresponds to r. An empty
+// region and regions containing wildcards are returned unmodified. It returns
+// false if r is not in the target partition and has no valid mapping.
+func (t *Translator) Region(r string) (string, bool) {
+	if r == "" || strings.ContainsAny(r, "*?") {
+		return r, true
+	}
+	once.Do(load)
+	if regionPart[r] == t.Partition {
+		return r, true
+	}
+	if m, ok := t.Regions[r]; ok && regionPart[m] == t.Partition {
+		return m, true
+	}
+	return r, false
+}
+
+// ARN returns r translated to the target partition. Partition wildcards are
+// replaced. It returns false if r is not a valid ARN, its region cannot be
+// mapped, or it identifies an AWS managed policy that is not known to exist in
+// the target partition.
+func (t *Translator) ARN(r arn.ARN) (arn.ARN, bool) {
+	once.Do(load)
+	if !r.Valid() || partRegions[t.Partition] == nil {
+		return r, false
+	}
+	if r.Partition() != t.Partition && r.Service() == "iam" &&
+		r.Account() == "aws" && strings.HasPrefix(r.Resource(), "policy/") {
+		name := r.Resource()[len("policy/"):]
+		if !strings.ContainsAny(name, "*?") && !t.ManagedPolicies[name] {
+			return r, false
+		}
+	}
+	region, ok := t.Region(r.Region())
+	if !ok {
+		return r, false
+	}
+	return r.WithPartition(t.Partition).WithRegion(region), true
+}
+
+// ServicePrincipal returns service principal name translated to the target
+// partition (e.g. "ec2.amazonaws.com" to "ec2.amazonaws.com.cn" for aws-cn).
+// It returns false if name does not end with a known partition DNS suffix.
+func (t *Translator) ServicePrincipal(name string) (string, bool) {
+	once.Do(load)
+	dst := partSuffix[t.Partition]
+	if dst == "" {
+		return name, false
+	}
+	for _, src := range suffixes {
+		if i := len(name) - len(src) - 1; i > 0 && name[i] == '.' &&
+			name[i+1:] == src {
+			return name[:i+1] + dst, true
+		}
+	}
+	return name, false
+}
@@ -0,0 +1,84 @@
+package region
+
+import (
+	"testing"
+
+	"github.com/mxk/go-cloud/aws/arn"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTranslator(t *testing.T) {
+	cn := Translator{
+		Partition: "aws-cn",
+		Regions:   map[string]string{"us-east-1": "cn-north-1", "x": "y"},
+		ManagedPolicies: map[string]bool{
+			"ReadOnlyAccess":             true,
+			"service-role/AWSConfigRole": true,
+		},
+	}
+	tests := []*struct {
+		in, out string
+		ok      bool
+	}{
+		{"", "", true},
+		{"*", "*", true},
+		{"cn-northwest-1", "cn-northwest-1", true},
+		{"us-east-1", "cn-north-1", true},
+		{"us-west-2", "us-west-2", false},
+		{"x", "x", false},
+	}
+	for _, tc := range tests {
+		out, ok := cn.Region(tc.in)
+		assert.Equal(t, tc.out, out, "%+v", tc)
+		assert.Equal(t, tc.ok, ok, "%+v", tc)
+	}
+
+	arns := []*struct {
+		in, out arn.ARN
+		ok      bool
+	}{
+		{"arn:aws:iam::123456789012:role/r", "arn:aws-cn:iam::123456789012:role/r", true},
+		{"arn:*:s3:::b", "arn:aws-cn:s3:::b", true},
+		{"arn:aws:sqs:us-east-1:123456789012:q", "arn:aws-cn:sqs:cn-north-1:123456789012:q", true},
+		{"arn:aws:sqs:us-west-2:123456789012:q", "arn:aws:sqs:us-west-2:123456789012:q", false},
+		{"x", "x", false},
+		{"arn:aws:iam::aws:policy/ReadOnlyAccess", "arn:aws-cn:iam::aws:policy/ReadOnlyAccess", true},
+		{"arn:aws:iam::aws:policy/service-role/AWSConfigRole", "arn:aws-cn:iam::aws:policy/service-role/AWSConfigRole", true},
+		{"arn:aws:iam::aws:policy/AWSSupportServiceRolePolicy", "arn:aws:iam::aws:policy/AWSSupportServiceRolePolicy", false},
+		{"arn:aws-cn:iam::aws:policy/AWSSupportServiceRolePolicy", "arn:aws-cn:iam::aws:policy/AWSSupportServiceRolePolicy", true},
+		{"arn:aws:iam::aws:policy/*", "arn:aws-cn:iam::aws:policy/*", true},
+		{"arn:aws:iam::123456789012:policy/p", "arn:aws-cn:iam::123456789012:policy/p", true},
+	}
+	for _, tc := range arns {
+		out, ok := cn.ARN(tc.in)
+		assert.Equal(t, tc.out, out, "%+v", tc)
+		assert.Equal(t, tc.ok, ok, "%+v", tc)
+	}
+	bad := Translator{Partition: "x"}
+	_, ok := bad.ARN("arn:aws:s3:::b")
+	assert.False(t, ok)
+
+	sps := []*struct {
+		in, out string
+		ok      bool
+	}{
+		{"ec2.amazonaws.com", "ec2.amazonaws.com.cn", true},
+		{"ec2.amazonaws.com.cn", "ec2.amazonaws.com.cn", true},
+		{"amazonaws.com", "amazonaws.com", false},
+		{"ec2.example.com", "ec2.example.com", false},
+	}
+	for _, tc := range sps {
+		out, ok := cn.ServicePrincipal(tc.in)
+		assert.Equal(t, tc.out, out, "%+v", tc)
+		assert.Equal(t, tc.ok, ok, "%+v", tc)
+	}
+	gov := Translator{Partition: "aws-us-gov"}
+	out, ok := gov.ServicePrincipal("lambda.amazonaws.com.cn")
+	assert.Equal(t, "lambda.amazonaws.com", out)
+	assert.True(t, ok)
+	_, ok = bad.ServicePrincipal("lambda.amazonaws.com")
+	assert.False(t, ok)
+
+	err := &UnmappedError{"aws-cn", []string{"a", "b"}}
+	assert.EqualError(t, err, "region: cannot translate to aws-cn: a, b")
+}